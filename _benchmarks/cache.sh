@@ -0,0 +1,77 @@
+#!/usr/bin/env bash
+# Compares the on-disk size of the cache and the wall time and peak
+# memory usage of cold and warm runs between two revisions of
+# staticcheck, for example before and after a change to the format of
+# cached data or to how cached data is kept in memory.
+#
+# Usage: ./cache.sh [old revision] [new revision]
+set -e
+
+declare -A PKGS=(
+	["strconv"]="strconv"
+	["std"]="std"
+)
+
+OLD=${1:-HEAD~1}
+NEW=${2:-HEAD}
+SAMPLES=5
+TMP=$(mktemp -d)
+trap 'rm -rf "$TMP"' EXIT
+
+buildRevision() {
+	local rev="$1"
+	local out="$2"
+
+	git worktree add -q --detach "$TMP/src" "$rev"
+	(cd "$TMP/src" && go build -o "$out" ./cmd/staticcheck)
+	git worktree remove --force "$TMP/src"
+}
+
+# cacheSize prints the total size of the entries in a cache directory,
+# excluding the directories themselves.
+cacheSize() {
+	find "$1" -type f \( -name '*-a' -o -name '*-d' \) -printf '%s\n' | awk '{ s += $1 } END { print s + 0 }'
+}
+
+# peakMemory runs staticcheck with the given cache and prints its peak
+# resident set size in KiB.
+peakMemory() {
+	local cache="$1"
+	local bin="$2"
+	local pkg="$3"
+
+	STATICCHECK_CACHE="$cache" env time -f "%M" -o "$TMP/mem" "$bin" -checks "all" -fail "" "$pkg" &>/dev/null || true
+	tail -n 1 "$TMP/mem"
+}
+
+runBenchmark() {
+	local bin="$1"
+	local rev="$2"
+	local pkg="$3"
+	local label="$4"
+
+	local cache="$TMP/cache"
+	rm -rf "$cache"
+	mkdir "$cache"
+
+	local start=$(date +%s%N)
+	local coldMem=$(peakMemory "$cache" "$bin" "$pkg")
+	local cold=$(date +%s%N)
+	local warmMem=$(peakMemory "$cache" "$bin" "$pkg")
+	local warm=$(date +%s%N)
+
+	printf "%s,%s,%d,%d,%d,%d,%d\n" "$label" "$rev" "$((cold-start))" "$((warm-cold))" "$((coldMem*1024))" "$((warmMem*1024))" "$(cacheSize "$cache")"
+}
+
+buildRevision "$OLD" "$TMP/old"
+buildRevision "$NEW" "$TMP/new"
+export GO111MODULE=off
+
+printf "packages,revision,cold-time,warm-time,cold-memory,warm-memory,cache-size\n"
+for label in "${!PKGS[@]}"; do
+	pkg=${PKGS[$label]}
+	for i in $(seq 1 $SAMPLES); do
+		runBenchmark "$TMP/old" "$OLD" "$pkg" "$label"
+		runBenchmark "$TMP/new" "$NEW" "$pkg" "$label"
+	done
+done
@@ -10,16 +10,35 @@ import (
 	"go/token"
 	"strconv"
 	"strings"
+	"sync"
+
+	"honnef.co/go/tools/config"
 
 	"golang.org/x/tools/go/analysis"
 )
 
 type Documentation struct {
-	Title      string
-	Text       string
-	Since      string
-	NonDefault bool
-	Options    []string
+	Title string
+	Text  string
+	// Before is an example of code that the check flags, and After
+	// is the same code after addressing the problem. Examples are
+	// unindented Go code.
+	Before string
+	After  string
+	// Related lists the names of checks that are related to this one
+	Related []string
+	// SeverityRationale explains how severe problems found by the
+	// check usually are, and why.
+	SeverityRationale string
+	Since             string
+	NonDefault        bool
+	Options           []string
+	// CheckOptions is a pointer to a struct holding the default values
+	// of the check's own options, which are set in the check's
+	// [options.<check>] table. See config.RegisterOptions.
+	CheckOptions interface{}
+	// Name of the check, set by InitializeAnalyzers
+	name string
 }
 
 func (doc *Documentation) String() string {
@@ -28,6 +47,18 @@ func (doc *Documentation) String() string {
 	if doc.Text != "" {
 		fmt.Fprintf(b, "%s\n\n", doc.Text)
 	}
+	if doc.Before != "" {
+		fmt.Fprintf(b, "Before:\n\n%s\n\n", indent(doc.Before))
+	}
+	if doc.After != "" {
+		fmt.Fprintf(b, "After:\n\n%s\n\n", indent(doc.After))
+	}
+	if doc.SeverityRationale != "" {
+		fmt.Fprintf(b, "Severity\n%s\n\n", indent(doc.SeverityRationale))
+	}
+	if len(doc.Related) > 0 {
+		fmt.Fprintf(b, "Related checks\n    %s\n\n", strings.Join(doc.Related, ", "))
+	}
 	fmt.Fprint(b, "Available since\n    ")
 	if doc.Since == "" {
 		fmt.Fprint(b, "unreleased")
@@ -38,16 +69,34 @@ func (doc *Documentation) String() string {
 		fmt.Fprint(b, ", non-default")
 	}
 	fmt.Fprint(b, "\n")
-	if len(doc.Options) > 0 {
+	if len(doc.Options) > 0 || doc.CheckOptions != nil {
 		fmt.Fprintf(b, "\nOptions\n")
 		for _, opt := range doc.Options {
-			fmt.Fprintf(b, "    %s", opt)
+			fmt.Fprintf(b, "    %s\n", opt)
+		}
+		if doc.CheckOptions != nil {
+			for _, opt := range config.DescribeOptions(doc.CheckOptions) {
+				fmt.Fprintf(b, "    options.%s.%s (default: %s)\n", doc.name, opt.Name, opt.Default)
+				if opt.Doc != "" {
+					fmt.Fprintf(b, "        %s\n", opt.Doc)
+				}
+			}
 		}
-		fmt.Fprint(b, "\n")
 	}
 	return b.String()
 }
 
+// indent indents all non-empty lines of s by four spaces.
+func indent(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		if l != "" {
+			lines[i] = "    " + l
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
 func newVersionFlag() flag.Getter {
 	tags := build.Default.ReleaseTags
 	v := tags[len(tags)-1][2:]
@@ -83,7 +132,22 @@ func (v *VersionFlag) Get() interface{} {
 	return int(*v)
 }
 
+var docsMu sync.RWMutex
+var docsByAnalyzer = map[*analysis.Analyzer]*Documentation{}
+
+// DocumentationOf returns the structured documentation of an analyzer
+// that was created by InitializeAnalyzers. It returns nil for all
+// other analyzers, whose only documentation is their Doc field.
+func DocumentationOf(a *analysis.Analyzer) *Documentation {
+	docsMu.RLock()
+	defer docsMu.RUnlock()
+	return docsByAnalyzer[a]
+}
+
 func InitializeAnalyzers(docs map[string]*Documentation, analyzers map[string]*analysis.Analyzer) map[string]*analysis.Analyzer {
+	docsMu.Lock()
+	defer docsMu.Unlock()
+
 	out := make(map[string]*analysis.Analyzer, len(analyzers))
 	for k, v := range analyzers {
 		vc := *v
@@ -94,7 +158,12 @@ func InitializeAnalyzers(docs map[string]*Documentation, analyzers map[string]*a
 		if !ok {
 			panic(fmt.Sprintf("missing documentation for check %s", k))
 		}
+		if doc.CheckOptions != nil {
+			doc.name = k
+			config.RegisterOptions(k, doc.CheckOptions)
+		}
 		vc.Doc = doc.String()
+		docsByAnalyzer[&vc] = doc
 		if vc.Flags.Usage == nil {
 			fs := flag.NewFlagSet("", flag.PanicOnError)
 			fs.Var(newVersionFlag(), "go", "Target Go version")
@@ -5,10 +5,14 @@ import (
 	"fmt"
 	"go/ast"
 	"go/token"
+	"io/ioutil"
 	"os"
+	"path"
 	"path/filepath"
 	"reflect"
+	"regexp"
 	"strings"
+	"unicode"
 
 	"github.com/BurntSushi/toml"
 	"golang.org/x/tools/go/analysis"
@@ -128,9 +132,192 @@ func (cfg Config) Merge(ocfg Config) Config {
 	if ocfg.HTTPStatusCodeWhitelist != nil {
 		cfg.HTTPStatusCodeWhitelist = mergeLists(cfg.HTTPStatusCodeWhitelist, ocfg.HTTPStatusCodeWhitelist)
 	}
+	if ocfg.Severity != nil {
+		cfg.Severity = mergeSeverities(cfg.Severity, ocfg.Severity)
+	}
+	if ocfg.Overrides != nil {
+		overrides := make([]Override, 0, len(cfg.Overrides)+len(ocfg.Overrides))
+		overrides = append(overrides, cfg.Overrides...)
+		cfg.Overrides = append(overrides, ocfg.Overrides...)
+	}
+	if ocfg.ExcludePaths != nil {
+		cfg.ExcludePaths = mergeLists(cfg.ExcludePaths, ocfg.ExcludePaths)
+	}
+	if ocfg.IgnoreReasonPattern != "" {
+		cfg.IgnoreReasonPattern = ocfg.IgnoreReasonPattern
+	}
+	if ocfg.Options != nil {
+		cfg.Options = mergeOptions(cfg.Options, ocfg.Options, ocfg.definedOptions)
+	}
 	return cfg
 }
 
+// An Override changes the set of enabled checks for files that match
+// any of its paths.
+type Override struct {
+	Paths  []string `toml:"paths"`
+	Checks []string `toml:"checks"`
+}
+
+// resolvePaths turns the path globs in cfg, which are relative to dir,
+// into absolute globs. Globs that don't contain a slash match files at
+// any depth, such as "*_mock.go".
+func (cfg *Config) resolvePaths(dir string) {
+	resolve := func(globs []string) []string {
+		out := make([]string, len(globs))
+		for i, glob := range globs {
+			switch {
+			case glob == "inherit":
+				out[i] = glob
+			case !strings.Contains(glob, "/"):
+				out[i] = filepath.ToSlash(dir) + "/**/" + glob
+			default:
+				out[i] = filepath.ToSlash(filepath.Join(dir, filepath.FromSlash(glob)))
+			}
+		}
+		return out
+	}
+	for i := range cfg.Overrides {
+		cfg.Overrides[i].Paths = resolve(cfg.Overrides[i].Paths)
+	}
+	if cfg.ExcludePaths != nil {
+		cfg.ExcludePaths = resolve(cfg.ExcludePaths)
+	}
+}
+
+// MatchPath reports whether the absolute path glob matches the file
+// name or any of its parent directories. Elements of the glob use the
+// syntax of path.Match, and "**" matches any number of path elements.
+func MatchPath(glob, name string) bool {
+	var match func(pat, elems []string) bool
+	match = func(pat, elems []string) bool {
+		for len(pat) > 0 {
+			if pat[0] == "**" {
+				for i := 0; i <= len(elems); i++ {
+					if match(pat[1:], elems[i:]) {
+						return true
+					}
+				}
+				return false
+			}
+			if len(elems) == 0 {
+				return false
+			}
+			if ok, _ := path.Match(pat[0], elems[0]); !ok {
+				return false
+			}
+			pat, elems = pat[1:], elems[1:]
+		}
+		// Either path itself or one of its parents matched
+		return true
+	}
+	return match(strings.Split(glob, "/"), strings.Split(filepath.ToSlash(name), "/"))
+}
+
+// ChecksFor returns the checks enabled for the file name, which are
+// the checks option modified by all overrides that match the file.
+func (cfg Config) ChecksFor(name string) []string {
+	checks := cfg.Checks
+	for _, o := range cfg.Overrides {
+		if o.Checks == nil {
+			continue
+		}
+		for _, glob := range o.Paths {
+			if MatchPath(glob, name) {
+				checks = mergeLists(checks, o.Checks)
+				break
+			}
+		}
+	}
+	return checks
+}
+
+// Excluded reports whether the package in the directory dir has been
+// excluded from analysis by the exclude_paths option.
+func (cfg Config) Excluded(dir string) bool {
+	if dir == "" {
+		return false
+	}
+	for _, glob := range cfg.ExcludePaths {
+		if MatchPath(glob, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeSeverities merges the severities of a child configuration, b,
+// into those of its parent, a. Patterns in b replace all patterns in
+// a that match a subset of the same checks, even more specific ones.
+// That is, "ST*" in b overrides both "ST*" and "ST1000" in a.
+func mergeSeverities(a, b map[string]string) map[string]string {
+	out := make(map[string]string, len(a)+len(b))
+	for pa, sev := range a {
+		covered := false
+		for pb := range b {
+			if coversPattern(pb, pa) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			out[pa] = sev
+		}
+	}
+	for pb, sev := range b {
+		out[pb] = sev
+	}
+	return out
+}
+
+// coversPattern reports whether every check matched by the pattern q
+// is also matched by the pattern p. Patterns use the same syntax as
+// the checks option, without support for negation.
+func coversPattern(p, q string) bool {
+	if p == "*" || p == "all" {
+		return true
+	}
+	if q == "*" || q == "all" {
+		return false
+	}
+	if !strings.HasSuffix(p, "*") {
+		return p == q
+	}
+	prefix := p[:len(p)-1]
+	isNumber := func(r rune) bool { return unicode.IsNumber(r) }
+	if strings.IndexFunc(prefix, isNumber) == -1 {
+		// p is a category glob such as S*, which matches S1000 but
+		// not SA1000.
+		qprefix := strings.TrimSuffix(q, "*")
+		if idx := strings.IndexFunc(qprefix, isNumber); idx >= 0 {
+			qprefix = qprefix[:idx]
+		} else if !strings.HasSuffix(q, "*") {
+			return false
+		}
+		return qprefix == prefix
+	}
+	return strings.HasPrefix(strings.TrimSuffix(q, "*"), prefix)
+}
+
+// Severities are the valid values of the severity option.
+var Severities = []string{"error", "warning", "info", "hint"}
+
+func validateSeverities(sevs map[string]string) error {
+	for check, sev := range sevs {
+		valid := false
+		for _, s := range Severities {
+			if sev == s {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("invalid severity %q for %q, must be one of %s", sev, check, strings.Join(Severities, ", "))
+		}
+	}
+	return nil
+}
+
 type Config struct {
 	// TODO(dh): this implementation makes it impossible for external
 	// clients to add their own checkers with configuration. At the
@@ -143,6 +330,30 @@ type Config struct {
 	Initialisms             []string `toml:"initialisms"`
 	DotImportWhitelist      []string `toml:"dot_import_whitelist"`
 	HTTPStatusCodeWhitelist []string `toml:"http_status_code_whitelist"`
+
+	// Severity maps check names and globs to the severity of the
+	// problems they report.
+	Severity map[string]string `toml:"severity"`
+
+	// Overrides change the enabled checks for individual files.
+	Overrides []Override `toml:"overrides"`
+	// ExcludePaths lists packages that should not be analyzed.
+	ExcludePaths []string `toml:"exclude_paths"`
+
+	// IgnoreReasonPattern is a regular expression that the reasons of
+	// linter directives must match, such as a ticket ID.
+	IgnoreReasonPattern string `toml:"ignore_reason_pattern"`
+
+	// Root stops the lookup of configuration files in parent
+	// directories. It isn't merged.
+	Root bool `toml:"root"`
+
+	// Options holds the options of individual checks, keyed by check
+	// name. See RegisterOptions.
+	Options map[string]interface{} `toml:"-"`
+	// The options that were set in a single configuration file, for
+	// merging.
+	definedOptions map[string]map[string]bool
 }
 
 func (c Config) String() string {
@@ -151,7 +362,12 @@ func (c Config) String() string {
 	fmt.Fprintf(buf, "Checks: %#v\n", c.Checks)
 	fmt.Fprintf(buf, "Initialisms: %#v\n", c.Initialisms)
 	fmt.Fprintf(buf, "DotImportWhitelist: %#v\n", c.DotImportWhitelist)
-	fmt.Fprintf(buf, "HTTPStatusCodeWhitelist: %#v", c.HTTPStatusCodeWhitelist)
+	fmt.Fprintf(buf, "HTTPStatusCodeWhitelist: %#v\n", c.HTTPStatusCodeWhitelist)
+	fmt.Fprintf(buf, "Severity: %#v\n", c.Severity)
+	fmt.Fprintf(buf, "Overrides: %#v\n", c.Overrides)
+	fmt.Fprintf(buf, "ExcludePaths: %#v\n", c.ExcludePaths)
+	fmt.Fprintf(buf, "IgnoreReasonPattern: %#v\n", c.IgnoreReasonPattern)
+	fmt.Fprintf(buf, "Options:\n%s", c.OptionsString())
 
 	return buf.String()
 }
@@ -169,43 +385,71 @@ var DefaultConfig = Config{
 	},
 	DotImportWhitelist:      []string{},
 	HTTPStatusCodeWhitelist: []string{"200", "400", "404", "500"},
+	Severity:                map[string]string{},
+	ExcludePaths:            []string{},
 }
 
 const ConfigName = "staticcheck.conf"
 
-func parseConfigs(dir string) ([]Config, error) {
-	var out []Config
+// GlobalConfigEnv is the name of the environment variable that
+// points to a user's global configuration file. The global
+// configuration applies to all packages and is merged just above the
+// default configuration.
+const GlobalConfigEnv = "STATICCHECK_CONFIG"
 
-	// TODO(dh): consider stopping at the GOPATH/module boundary
+// Files returns the paths of the configuration files that apply to
+// packages in dir, from the top-most to the closest one.
+//
+// Lookup starts in dir and proceeds upwards. It stops at the
+// directory containing the go.mod file of the package's module, or at
+// a configuration file that sets root = true, whichever comes first.
+// The global configuration file, if any, always comes first, unless
+// it is also found by the lookup, in which case it is only included
+// once, in the position of the lookup.
+func Files(dir string) ([]string, error) {
+	var out []string
 	for dir != "" {
-		f, err := os.Open(filepath.Join(dir, ConfigName))
-		if os.IsNotExist(err) {
-			ndir := filepath.Dir(dir)
-			if ndir == dir {
+		path := filepath.Join(dir, ConfigName)
+		if _, err := os.Stat(path); err == nil {
+			out = append(out, path)
+			root, err := isRoot(path)
+			if err != nil {
+				return nil, err
+			}
+			if root {
 				break
 			}
-			dir = ndir
-			continue
-		}
-		if err != nil {
+		} else if !os.IsNotExist(err) {
 			return nil, err
 		}
-		var cfg Config
-		_, err = toml.DecodeReader(f, &cfg)
-		f.Close()
-		if err != nil {
-			return nil, err
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			// Configuration files outside the module don't belong to it
+			break
 		}
-		out = append(out, cfg)
 		ndir := filepath.Dir(dir)
 		if ndir == dir {
 			break
 		}
 		dir = ndir
 	}
-	out = append(out, DefaultConfig)
-	if len(out) < 2 {
-		return out, nil
+	if global := os.Getenv(GlobalConfigEnv); global != "" {
+		if !filepath.IsAbs(global) {
+			return nil, fmt.Errorf("%s is not an absolute path", GlobalConfigEnv)
+		}
+		if _, err := os.Stat(global); err != nil {
+			return nil, err
+		}
+		global = filepath.Clean(global)
+		found := false
+		for _, path := range out {
+			if path == global {
+				found = true
+				break
+			}
+		}
+		if !found {
+			out = append(out, global)
+		}
 	}
 	for i := 0; i < len(out)/2; i++ {
 		out[i], out[len(out)-1-i] = out[len(out)-1-i], out[i]
@@ -213,6 +457,89 @@ func parseConfigs(dir string) ([]Config, error) {
 	return out, nil
 }
 
+// isRoot reports whether the configuration file at path sets root =
+// true.
+func isRoot(path string) (bool, error) {
+	var cfg struct {
+		Root bool `toml:"root"`
+	}
+	if _, err := toml.DecodeFile(path, &cfg); err != nil {
+		return false, err
+	}
+	return cfg.Root, nil
+}
+
+// A configFile is a single parsed configuration file.
+type configFile struct {
+	path string
+	cfg  Config
+	// The metadata of decoding the file into a Config
+	md toml.MetaData
+	// Keys that don't correspond to any option
+	undecoded []toml.Key
+}
+
+func parseConfig(path string) (configFile, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return configFile{}, err
+	}
+	cf := configFile{path: path}
+	md, err := toml.Decode(string(b), &cf.cfg)
+	if err != nil {
+		return configFile{}, err
+	}
+	cf.md = md
+	var raw struct {
+		Options map[string]toml.Primitive `toml:"options"`
+	}
+	omd, err := toml.Decode(string(b), &raw)
+	if err != nil {
+		return configFile{}, err
+	}
+	cf.cfg.Options, cf.cfg.definedOptions, err = decodeOptions(omd, raw.Options)
+	if err != nil {
+		return configFile{}, fmt.Errorf("%s: %s", path, err)
+	}
+	if err := validateSeverities(cf.cfg.Severity); err != nil {
+		return configFile{}, fmt.Errorf("%s: %s", path, err)
+	}
+	if _, err := regexp.Compile(cf.cfg.IgnoreReasonPattern); err != nil {
+		return configFile{}, fmt.Errorf("%s: invalid ignore_reason_pattern: %s", path, err)
+	}
+	cf.cfg.resolvePaths(filepath.Dir(path))
+
+	// A key is undecoded if neither decoding into a Config nor
+	// decoding the options used it.
+	optionKeys := map[string]bool{}
+	for _, k := range omd.Undecoded() {
+		optionKeys[k.String()] = true
+	}
+	for _, k := range md.Undecoded() {
+		if optionKeys[k.String()] {
+			cf.undecoded = append(cf.undecoded, k)
+		}
+	}
+	return cf, nil
+}
+
+func parseConfigs(dir string) ([]Config, error) {
+	paths, err := Files(dir)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Config, 0, len(paths)+1)
+	out = append(out, DefaultConfig)
+	for _, path := range paths {
+		cf, err := parseConfig(path)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, cf.cfg)
+	}
+	return out, nil
+}
+
 func mergeConfigs(confs []Config) Config {
 	if len(confs) == 0 {
 		// This shouldn't happen because we always have at least a
@@ -240,6 +567,7 @@ func Load(dir string) (Config, error) {
 	conf.Initialisms = normalizeList(conf.Initialisms)
 	conf.DotImportWhitelist = normalizeList(conf.DotImportWhitelist)
 	conf.HTTPStatusCodeWhitelist = normalizeList(conf.HTTPStatusCodeWhitelist)
+	conf.ExcludePaths = normalizeList(conf.ExcludePaths)
 
 	return conf, nil
 }
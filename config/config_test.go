@@ -0,0 +1,239 @@
+package config
+
+import (
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+)
+
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		glob string
+		name string
+		want bool
+	}{
+		{"/src/internal/legacy/**", "/src/internal/legacy/a.go", true},
+		{"/src/internal/legacy/**", "/src/internal/legacy/sub/a.go", true},
+		{"/src/internal/legacy/**", "/src/internal/legacy", true},
+		{"/src/internal/legacy/**", "/src/internal/legacyfoo/a.go", false},
+		{"/src/internal/legacy", "/src/internal/legacy/a.go", true},
+		{"/src/**/*_mock.go", "/src/a_mock.go", true},
+		{"/src/**/*_mock.go", "/src/pkg/sub/a_mock.go", true},
+		{"/src/**/*_mock.go", "/src/pkg/a.go", false},
+		{"/src/**/*_mock.go", "/other/a_mock.go", false},
+		{"/src/*/gen", "/src/pkg/gen/a.go", true},
+		{"/src/*/gen", "/src/pkg/sub/gen/a.go", false},
+	}
+	for _, tt := range tests {
+		if got := MatchPath(tt.glob, tt.name); got != tt.want {
+			t.Errorf("MatchPath(%q, %q) = %t, want %t", tt.glob, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestChecksFor(t *testing.T) {
+	cfg := Config{Checks: []string{"all"}}
+	child := Config{Overrides: []Override{
+		{Paths: []string{"internal/legacy/**"}, Checks: []string{"inherit", "-SA1019"}},
+		{Paths: []string{"*_mock.go"}, Checks: []string{"-*"}},
+	}}
+	child.resolvePaths("/src")
+	cfg = cfg.Merge(child)
+
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"/src/a.go", []string{"all"}},
+		{"/src/internal/legacy/a.go", []string{"all", "-SA1019"}},
+		{"/src/internal/legacy/a_mock.go", []string{"-*"}},
+	}
+	for _, tt := range tests {
+		if got := cfg.ChecksFor(tt.name); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ChecksFor(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMergeSeverities(t *testing.T) {
+	parent := map[string]string{"ST1000": "error", "ST1*": "warning", "SA*": "info", "S1000": "error"}
+	child := map[string]string{"ST*": "hint", "SA1*": "error"}
+	got := mergeSeverities(parent, child)
+	want := map[string]string{"SA*": "info", "S1000": "error", "ST*": "hint", "SA1*": "error"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+type testOptions struct {
+	Names    []string          `toml:"names"`
+	Messages map[string]string `toml:"messages"`
+	Limit    int               `toml:"limit"`
+}
+
+func TestOptions(t *testing.T) {
+	RegisterOptions("XX1000", &testOptions{
+		Names:    []string{"a"},
+		Messages: map[string]string{},
+		Limit:    10,
+	})
+
+	parse := func(s string) Config {
+		var cfg Config
+		var raw struct {
+			Options map[string]toml.Primitive `toml:"options"`
+		}
+		md, err := toml.Decode(s, &raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cfg.Options, cfg.definedOptions, err = decodeOptions(md, raw.Options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cfg
+	}
+
+	parent := parse(`
+[options.XX1000]
+names = ["inherit", "b"]
+messages = {x = "1"}
+`)
+	child := parse(`
+[options.XX1000]
+names = ["inherit", "c"]
+messages = {y = "2"}
+limit = 0
+`)
+	cfg := DefaultConfig.Merge(parent).Merge(child)
+	got := cfg.OptionsFor("XX1000").(*testOptions)
+	want := &testOptions{
+		Names:    []string{"a", "b", "c"},
+		Messages: map[string]string{"x": "1", "y": "2"},
+		Limit:    0,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v, want %#v", got, want)
+	}
+
+	if got := DefaultConfig.OptionsFor("XX1000").(*testOptions); got.Limit != 10 {
+		t.Errorf("got limit %d from default configuration, want 10", got.Limit)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "staticcheck")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, ConfigName)
+	const text = `checks = ["all",
+	"-SA9999"]
+dot_import_whitelists = []
+
+[severity]
+"ST*" = "hint"
+"XY*" = "error"
+
+[[overrides]]
+paths = ["a"]
+checks = ["-SA1000"]
+
+[[overrides]]
+paths = ["b"]
+checks = ["-SA2000"]
+
+[options.SA1000]
+x = 1
+`
+	if err := ioutil.WriteFile(path, []byte(text), 0666); err != nil {
+		t.Fatal(err)
+	}
+	problems, err := Validate(path, []string{"SA1000", "SA1001", "ST1000"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Problem{
+		{token.Position{Filename: path, Line: 2, Column: 2}, `"-SA9999" doesn't match any check`},
+		{token.Position{Filename: path, Line: 3, Column: 1}, `unknown configuration key "dot_import_whitelists"`},
+		{token.Position{Filename: path, Line: 7, Column: 1}, `"XY*" doesn't match any check`},
+		{token.Position{Filename: path, Line: 15, Column: 11}, `"-SA2000" doesn't match any check`},
+		{token.Position{Filename: path, Line: 17, Column: 1}, `check SA1000 has no options`},
+	}
+	if !reflect.DeepEqual(problems, want) {
+		t.Errorf("got %v, want %v", problems, want)
+	}
+}
+
+func TestFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "staticcheck")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer os.Setenv(GlobalConfigEnv, os.Getenv(GlobalConfigEnv))
+	os.Setenv(GlobalConfigEnv, "")
+
+	write := func(name, text string) string {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(text), 0666); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	global := write("global.conf", "")
+	write(ConfigName, "")
+	write("mod/go.mod", "module example.com/mod\n")
+	modConf := write("mod/"+ConfigName, "")
+	rootConf := write("mod/a/"+ConfigName, "root = true\n")
+	nestedConf := write("mod/a/b/"+ConfigName, "")
+	os.MkdirAll(filepath.Join(dir, "mod", "c"), 0777)
+
+	tests := []struct {
+		dir  string
+		want []string
+	}{
+		{"mod/c", []string{modConf}},
+		{"mod/a/b", []string{rootConf, nestedConf}},
+	}
+	for _, tt := range tests {
+		got, err := Files(filepath.Join(dir, filepath.FromSlash(tt.dir)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Files(%q) = %v, want %v", tt.dir, got, tt.want)
+		}
+	}
+
+	os.Setenv(GlobalConfigEnv, global)
+	got, err := Files(filepath.Join(dir, "mod", "c"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{global, modConf}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	// The global configuration is also found by the lookup
+	os.Setenv(GlobalConfigEnv, filepath.Join(dir, "mod")+string(filepath.Separator)+"."+string(filepath.Separator)+ConfigName)
+	got, err = Files(filepath.Join(dir, "mod", "c"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{modConf}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	os.Setenv(GlobalConfigEnv, "global.conf")
+	if _, err := Files(filepath.Join(dir, "mod", "c")); err == nil {
+		t.Error("expected error for relative path in " + GlobalConfigEnv)
+	}
+}
@@ -0,0 +1,217 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Checks can declare their own options, which are configured in
+// [options.<check>] tables. The options of a check are described by a
+// struct, whose fields use toml tags for their names and doc tags for
+// their documentation.
+//
+// Fields of type []string are merged like other lists and support
+// "inherit". Fields of type map[string]string are merged key by key.
+// All other fields are replaced when set in a configuration file.
+
+var optionsMu sync.RWMutex
+var optionDefaults = map[string]interface{}{}
+
+// RegisterOptions declares the options of a check. defaults must be a
+// pointer to a struct holding the default values of the options. It
+// panics if the check's options have already been registered.
+func RegisterOptions(check string, defaults interface{}) {
+	v := reflect.ValueOf(defaults)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("options of %s must be a pointer to a struct, not %T", check, defaults))
+	}
+	optionsMu.Lock()
+	defer optionsMu.Unlock()
+	if _, ok := optionDefaults[check]; ok {
+		panic(fmt.Sprintf("options of %s registered twice", check))
+	}
+	optionDefaults[check] = defaults
+}
+
+func registeredOptions(check string) (interface{}, bool) {
+	optionsMu.RLock()
+	defer optionsMu.RUnlock()
+	defaults, ok := optionDefaults[check]
+	return defaults, ok
+}
+
+// OptionsFor returns the options of check, as a pointer to the type
+// registered with RegisterOptions. It returns nil if the check has no
+// options.
+func (cfg *Config) OptionsFor(check string) interface{} {
+	if opts, ok := cfg.Options[check]; ok {
+		return opts
+	}
+	defaults, _ := registeredOptions(check)
+	return defaults
+}
+
+// An OptionDoc documents a single option of a check.
+type OptionDoc struct {
+	Name    string
+	Doc     string
+	Default string
+}
+
+// DescribeOptions documents the options described by the struct that
+// defaults points to.
+func DescribeOptions(defaults interface{}) []OptionDoc {
+	v := reflect.ValueOf(defaults).Elem()
+	var out []OptionDoc
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		name := optionName(field)
+		if name == "" {
+			continue
+		}
+		out = append(out, OptionDoc{
+			Name:    name,
+			Doc:     field.Tag.Get("doc"),
+			Default: formatOption(v.Field(i)),
+		})
+	}
+	return out
+}
+
+func optionName(field reflect.StructField) string {
+	if field.PkgPath != "" {
+		// unexported
+		return ""
+	}
+	name := field.Tag.Get("toml")
+	if idx := strings.IndexByte(name, ','); idx >= 0 {
+		name = name[:idx]
+	}
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		name = field.Name
+	}
+	return name
+}
+
+// formatOption formats an option's value using TOML syntax.
+func formatOption(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Slice:
+		elems := make([]string, v.Len())
+		for i := range elems {
+			elems[i] = formatOption(v.Index(i))
+		}
+		return "[" + strings.Join(elems, ", ") + "]"
+	case reflect.Map:
+		keys := v.MapKeys()
+		elems := make([]string, len(keys))
+		for i, k := range keys {
+			elems[i] = fmt.Sprintf("%q = %s", k.String(), formatOption(v.MapIndex(k)))
+		}
+		sort.Strings(elems)
+		return "{" + strings.Join(elems, ", ") + "}"
+	case reflect.String:
+		return fmt.Sprintf("%q", v.String())
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
+
+// decodeOptions decodes the [options.<check>] tables of a
+// configuration file. It records which options were set, for use by
+// mergeOptions. Tables of checks without options are skipped.
+func decodeOptions(md toml.MetaData, raw map[string]toml.Primitive) (map[string]interface{}, map[string]map[string]bool, error) {
+	if len(raw) == 0 {
+		return nil, nil, nil
+	}
+	opts := map[string]interface{}{}
+	defined := map[string]map[string]bool{}
+	for check, prim := range raw {
+		defaults, ok := registeredOptions(check)
+		if !ok {
+			// Validate reports these as unknown keys.
+			continue
+		}
+		v := reflect.New(reflect.TypeOf(defaults).Elem())
+		if err := md.PrimitiveDecode(prim, v.Interface()); err != nil {
+			return nil, nil, fmt.Errorf("options of %s: %s", check, err)
+		}
+		set := map[string]bool{}
+		for i := 0; i < v.Elem().NumField(); i++ {
+			name := optionName(v.Elem().Type().Field(i))
+			if name != "" && md.IsDefined("options", check, name) {
+				set[name] = true
+			}
+		}
+		opts[check] = v.Interface()
+		defined[check] = set
+	}
+	return opts, defined, nil
+}
+
+// mergeOptions merges the options set in a child configuration, b,
+// into those of its parent, a.
+func mergeOptions(a, b map[string]interface{}, defined map[string]map[string]bool) map[string]interface{} {
+	out := make(map[string]interface{}, len(a)+len(b))
+	for check, opts := range a {
+		out[check] = opts
+	}
+	for check, opts := range b {
+		base, ok := a[check]
+		if !ok {
+			base, _ = registeredOptions(check)
+		}
+		bv := reflect.ValueOf(base).Elem()
+		ov := reflect.ValueOf(opts).Elem()
+		nv := reflect.New(bv.Type()).Elem()
+		nv.Set(bv)
+		for i := 0; i < nv.NumField(); i++ {
+			name := optionName(nv.Type().Field(i))
+			if name == "" || !defined[check][name] {
+				continue
+			}
+			field := nv.Field(i)
+			switch {
+			case field.Type() == reflect.TypeOf([]string(nil)):
+				field.Set(reflect.ValueOf(mergeLists(field.Interface().([]string), ov.Field(i).Interface().([]string))))
+			case field.Type() == reflect.TypeOf(map[string]string(nil)):
+				m := map[string]string{}
+				for k, v := range field.Interface().(map[string]string) {
+					m[k] = v
+				}
+				for k, v := range ov.Field(i).Interface().(map[string]string) {
+					m[k] = v
+				}
+				field.Set(reflect.ValueOf(m))
+			default:
+				field.Set(ov.Field(i))
+			}
+		}
+		out[check] = nv.Addr().Interface()
+	}
+	return out
+}
+
+// OptionsString formats the options of all checks in a stable order.
+// Unlike formatting Options with %#v, the output doesn't include
+// pointer values and is suitable for hashing.
+func (cfg Config) OptionsString() string {
+	checks := make([]string, 0, len(cfg.Options))
+	for check := range cfg.Options {
+		checks = append(checks, check)
+	}
+	sort.Strings(checks)
+	var b strings.Builder
+	for _, check := range checks {
+		fmt.Fprintf(&b, "%s: %#v\n", check, reflect.ValueOf(cfg.Options[check]).Elem().Interface())
+	}
+	return b.String()
+}
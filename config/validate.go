@@ -0,0 +1,411 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"go/token"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/BurntSushi/toml"
+)
+
+// A Problem is a problem in a configuration file.
+type Problem struct {
+	Position token.Position
+	Message  string
+}
+
+// Validate checks the configuration file at path for keys that don't
+// correspond to any option, and for check names that don't match any
+// of analyzers. Errors are only returned for files that can't be
+// parsed at all.
+func Validate(path string, analyzers []string) ([]Problem, error) {
+	cf, err := parseConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	text, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	loc := newLocator(path, text)
+
+	known := func(pattern string) bool {
+		pattern = strings.TrimPrefix(pattern, "-")
+		if pattern == "inherit" || pattern == "all" || pattern == "*" {
+			return true
+		}
+		for _, a := range analyzers {
+			if coversPattern(pattern, a) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var problems []Problem
+	undecoded := map[string]bool{}
+	for _, k := range cf.undecoded {
+		undecoded[k.String()] = true
+	}
+	reportedOptions := map[string]bool{}
+	for _, k := range cf.undecoded {
+		if len(k) >= 2 && k[0] == "options" {
+			if _, ok := registeredOptions(k[1]); !ok {
+				// The entire table is unknown
+				if reportedOptions[k[1]] {
+					continue
+				}
+				reportedOptions[k[1]] = true
+				msg := fmt.Sprintf("unknown check %q", k[1])
+				if known(k[1]) {
+					msg = fmt.Sprintf("check %s has no options", k[1])
+				}
+				problems = append(problems, Problem{Position: loc.key(k[:2]), Message: msg})
+				continue
+			}
+		}
+		if len(k) > 1 && undecoded[k[:len(k)-1].String()] {
+			// Only report the outermost unknown key
+			continue
+		}
+		problems = append(problems, Problem{
+			Position: loc.key(k),
+			Message:  fmt.Sprintf("unknown configuration key %q", k.String()),
+		})
+	}
+
+	checkList := func(key toml.Key, checks []string) {
+		for _, c := range checks {
+			if !known(c) {
+				problems = append(problems, Problem{
+					Position: loc.value(key, c),
+					Message:  fmt.Sprintf("%q doesn't match any check", c),
+				})
+			}
+		}
+	}
+	checkList(toml.Key{"checks"}, cf.cfg.Checks)
+	for i, o := range cf.cfg.Overrides {
+		checkList(toml.Key{"overrides", fmt.Sprint(i), "checks"}, o.Checks)
+	}
+	patterns := make([]string, 0, len(cf.cfg.Severity))
+	for pattern := range cf.cfg.Severity {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+	for _, pattern := range patterns {
+		if strings.HasPrefix(pattern, "-") || pattern == "inherit" || !known(pattern) {
+			problems = append(problems, Problem{
+				Position: loc.key(toml.Key{"severity", pattern}),
+				Message:  fmt.Sprintf("%q doesn't match any check", pattern),
+			})
+		}
+	}
+
+	sort.SliceStable(problems, func(i, j int) bool {
+		return problems[i].Position.Line < problems[j].Position.Line
+	})
+	return problems, nil
+}
+
+// A locator finds the positions of keys and values in a TOML file.
+// The TOML parser doesn't record positions, so we look for them
+// ourselves. This handles the common cases of tables, arrays of
+// tables and keys on their own lines; for everything else, the
+// position of the closest enclosing key is used.
+type locator struct {
+	path  string
+	lines []string
+	// The full key of each line, if it has one
+	keys []toml.Key
+}
+
+func newLocator(path string, text []byte) *locator {
+	loc := &locator{path: path}
+	var table toml.Key
+	counts := map[string]int{}
+	for _, line := range strings.Split(string(text), "\n") {
+		loc.lines = append(loc.lines, line)
+		trimmed := strings.TrimSpace(line)
+		var key toml.Key
+		switch {
+		case strings.HasPrefix(trimmed, "[["):
+			end := strings.Index(trimmed, "]]")
+			if end == -1 {
+				break
+			}
+			name := splitKey(trimmed[2:end])
+			// Arrays of tables are indexed like toml.MetaData does
+			idx := counts[name.String()]
+			counts[name.String()]++
+			table = append(name, fmt.Sprint(idx))
+			key = table
+		case strings.HasPrefix(trimmed, "["):
+			end := strings.LastIndex(trimmed, "]")
+			if end == -1 {
+				break
+			}
+			table = splitKey(trimmed[1:end])
+			key = table
+		case trimmed == "" || trimmed[0] == '#':
+		default:
+			if idx := keyEnd(trimmed); idx > 0 {
+				key = append(append(toml.Key{}, table...), splitKey(trimmed[:idx])...)
+			}
+		}
+		loc.keys = append(loc.keys, key)
+	}
+	return loc
+}
+
+// keyEnd returns the offset of the '=' that ends the key in line.
+func keyEnd(line string) int {
+	inQuote := byte(0)
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case inQuote != 0:
+			if c == inQuote {
+				inQuote = 0
+			}
+		case c == '"' || c == '\'':
+			inQuote = c
+		case c == '=':
+			return i
+		}
+	}
+	return -1
+}
+
+// splitKey splits a possibly dotted and quoted key into its parts.
+func splitKey(s string) toml.Key {
+	var key toml.Key
+	var cur strings.Builder
+	inQuote := rune(0)
+	for _, r := range s {
+		switch {
+		case inQuote != 0:
+			if r == inQuote {
+				inQuote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			inQuote = r
+		case r == '.':
+			key = append(key, cur.String())
+			cur.Reset()
+		case unicode.IsSpace(r):
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	return append(key, cur.String())
+}
+
+func hasPrefix(k, prefix toml.Key) bool {
+	if len(prefix) > len(k) {
+		return false
+	}
+	for i := range prefix {
+		if k[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// line returns the index of the line that defines key, or of the line
+// defining its longest prefix. It returns -1 if nothing matches.
+func (loc *locator) line(key toml.Key) int {
+	best, bestLen := -1, 0
+	for i, k := range loc.keys {
+		if len(k) == 0 || !hasPrefix(key, k) {
+			continue
+		}
+		if len(k) == len(key) {
+			return i
+		}
+		if len(k) > bestLen {
+			best, bestLen = i, len(k)
+		}
+	}
+	return best
+}
+
+func (loc *locator) position(line, col int) token.Position {
+	if line == -1 {
+		return token.Position{Filename: loc.path}
+	}
+	return token.Position{Filename: loc.path, Line: line + 1, Column: col + 1}
+}
+
+func (loc *locator) key(key toml.Key) token.Position {
+	i := loc.line(key)
+	if i == -1 {
+		return loc.position(-1, 0)
+	}
+	col := strings.IndexFunc(loc.lines[i], func(r rune) bool { return !unicode.IsSpace(r) })
+	if col == -1 {
+		col = 0
+	}
+	return loc.position(i, col)
+}
+
+// value returns the position of the string value in the array
+// assigned to key, which may span multiple lines.
+func (loc *locator) value(key toml.Key, value string) token.Position {
+	i := loc.line(key)
+	if i == -1 {
+		return loc.position(-1, 0)
+	}
+	quoted := fmt.Sprintf("%q", value)
+	for j := i; j < len(loc.lines); j++ {
+		if j > i && len(loc.keys[j]) > 0 {
+			// Reached the next key
+			break
+		}
+		if col := strings.Index(loc.lines[j], quoted); col >= 0 {
+			return loc.position(j, col)
+		}
+	}
+	return loc.key(key)
+}
+
+// Describe writes the effective configuration for packages in dir to
+// w, annotating each value with the configuration files it came from.
+func Describe(w io.Writer, dir string) error {
+	paths, err := Files(dir)
+	if err != nil {
+		return err
+	}
+	files := make([]configFile, len(paths))
+	for i, path := range paths {
+		files[i], err = parseConfig(path)
+		if err != nil {
+			return err
+		}
+	}
+	cfg, err := Load(dir)
+	if err != nil {
+		return err
+	}
+
+	const defaultSource = "(default)"
+	// sources returns the files that contributed to the value of key.
+	// Lists that use "inherit" and tables are made up of multiple
+	// files.
+	sources := func(key ...string) []string {
+		out := []string{defaultSource}
+		for _, f := range files {
+			if !f.md.IsDefined(key...) {
+				continue
+			}
+			// Tables are merged key by key
+			inherits := f.md.Type(key...) == "Hash"
+			if f.md.Type(key...) == "Array" {
+				v := reflect.ValueOf(lookupKey(f, key))
+				for i := 0; v.IsValid() && v.Kind() == reflect.Slice && i < v.Len(); i++ {
+					if v.Index(i).Kind() == reflect.String && v.Index(i).String() == "inherit" {
+						inherits = true
+					}
+				}
+			}
+			if inherits {
+				out = append(out, f.path)
+			} else {
+				out = []string{f.path}
+			}
+		}
+		return out
+	}
+
+	buf := &bytes.Buffer{}
+	value := func(name string, v interface{}, srcs []string) {
+		fmt.Fprintf(buf, "%s = %s\n", name, formatOption(reflect.ValueOf(v)))
+		fmt.Fprintf(buf, "\t# from %s\n", strings.Join(srcs, ", "))
+	}
+
+	value("checks", cfg.Checks, sources("checks"))
+	value("initialisms", cfg.Initialisms, sources("initialisms"))
+	value("dot_import_whitelist", cfg.DotImportWhitelist, sources("dot_import_whitelist"))
+	value("http_status_code_whitelist", cfg.HTTPStatusCodeWhitelist, sources("http_status_code_whitelist"))
+	value("exclude_paths", cfg.ExcludePaths, sources("exclude_paths"))
+	value("ignore_reason_pattern", cfg.IgnoreReasonPattern, sources("ignore_reason_pattern"))
+
+	if len(cfg.Severity) > 0 {
+		fmt.Fprintf(buf, "\n[severity]\n")
+		patterns := make([]string, 0, len(cfg.Severity))
+		for pattern := range cfg.Severity {
+			patterns = append(patterns, pattern)
+		}
+		sort.Strings(patterns)
+		for _, pattern := range patterns {
+			value(fmt.Sprintf("%q", pattern), cfg.Severity[pattern], sources("severity", pattern))
+		}
+	}
+
+	for _, f := range files {
+		for _, o := range f.cfg.Overrides {
+			fmt.Fprintf(buf, "\n[[overrides]]\n\t# from %s\n", f.path)
+			fmt.Fprintf(buf, "paths = %s\n", formatOption(reflect.ValueOf(o.Paths)))
+			fmt.Fprintf(buf, "checks = %s\n", formatOption(reflect.ValueOf(o.Checks)))
+		}
+	}
+
+	checks := make([]string, 0, len(cfg.Options))
+	for check := range cfg.Options {
+		checks = append(checks, check)
+	}
+	sort.Strings(checks)
+	for _, check := range checks {
+		fmt.Fprintf(buf, "\n[options.%s]\n", check)
+		for _, opt := range DescribeOptions(cfg.Options[check]) {
+			fmt.Fprintf(buf, "%s = %s\n", opt.Name, opt.Default)
+			fmt.Fprintf(buf, "\t# from %s\n", strings.Join(sources("options", check, opt.Name), ", "))
+		}
+	}
+
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// lookupKey returns the value of key in f, as decoded into a Config.
+// It only supports the keys that Describe needs to look at.
+func lookupKey(f configFile, key []string) interface{} {
+	switch {
+	case len(key) == 1:
+		switch key[0] {
+		case "checks":
+			return f.cfg.Checks
+		case "initialisms":
+			return f.cfg.Initialisms
+		case "dot_import_whitelist":
+			return f.cfg.DotImportWhitelist
+		case "http_status_code_whitelist":
+			return f.cfg.HTTPStatusCodeWhitelist
+		case "exclude_paths":
+			return f.cfg.ExcludePaths
+		case "ignore_reason_pattern":
+			return f.cfg.IgnoreReasonPattern
+		}
+	case len(key) == 3 && key[0] == "options":
+		opts, ok := f.cfg.Options[key[1]]
+		if !ok {
+			return nil
+		}
+		v := reflect.ValueOf(opts).Elem()
+		for i := 0; i < v.NumField(); i++ {
+			if optionName(v.Type().Field(i)) == key[2] {
+				return v.Field(i).Interface()
+			}
+		}
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package loader
 
 import (
 	"fmt"
+	"os"
 	"runtime"
 	"sort"
 	"strings"
@@ -12,9 +13,9 @@ import (
 // computeHash computes a package's hash. The hash is based on all Go
 // files that make up the package, as well as the hashes of imported
 // packages.
-func computeHash(pkg *PackageSpec) (cache.ActionID, error) {
+func computeHash(pkg *PackageSpec, goos, goarch string) (cache.ActionID, error) {
 	key := cache.NewHash("package " + pkg.PkgPath)
-	fmt.Fprintf(key, "goos %s goarch %s\n", runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(key, "goos %s goarch %s\n", goos, goarch)
 	fmt.Fprintf(key, "import %q\n", pkg.PkgPath)
 
 	// Compute the hashes of all files making up the package. As an
@@ -81,3 +82,21 @@ func getBuildid(f string) (string, error) {
 	buildidCache[f] = h
 	return h, nil
 }
+
+// targetPlatform returns the GOOS and GOARCH that packages are loaded
+// for, given the environment of go list.
+func targetPlatform(env []string) (goos, goarch string) {
+	if env == nil {
+		env = os.Environ()
+	}
+	goos, goarch = runtime.GOOS, runtime.GOARCH
+	// Like os/exec, later entries take precedence.
+	for _, kv := range env {
+		if strings.HasPrefix(kv, "GOOS=") && kv != "GOOS=" {
+			goos = kv[len("GOOS="):]
+		} else if strings.HasPrefix(kv, "GOARCH=") && kv != "GOARCH=" {
+			goarch = kv[len("GOARCH="):]
+		}
+	}
+	return goos, goarch
+}
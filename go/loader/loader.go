@@ -14,6 +14,7 @@ import (
 	"honnef.co/go/tools/config"
 	"honnef.co/go/tools/internal/cache"
 	"honnef.co/go/tools/internal/go/gcimporter"
+	"honnef.co/go/tools/internal/memcache"
 
 	"golang.org/x/tools/go/packages"
 )
@@ -39,6 +40,13 @@ type PackageSpec struct {
 	Hash            cache.ActionID
 
 	Config config.Config
+
+	// Whether the package's export data is out of date because the
+	// package or one of its dependencies changed after the graph was
+	// loaded. See Refresh.
+	stale bool
+	// The hash computed by Graph, before the package became stale
+	graphHash cache.ActionID
 }
 
 func (spec *PackageSpec) String() string {
@@ -80,6 +88,7 @@ func Graph(cfg *packages.Config, patterns ...string) ([]*PackageSpec, error) {
 		return nil, err
 	}
 
+	goos, goarch := targetPlatform(dcfg.Env)
 	m := map[*packages.Package]*PackageSpec{}
 	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
 		spec := &PackageSpec{
@@ -106,7 +115,7 @@ func Graph(cfg *packages.Config, patterns ...string) ([]*PackageSpec, error) {
 		} else {
 			spec.Config = config.DefaultConfig
 		}
-		spec.Hash, err = computeHash(spec)
+		spec.Hash, err = computeHash(spec, goos, goarch)
 		if err != nil {
 			spec.Errors = append(spec.Errors, convertError(err)...)
 		}
@@ -134,6 +143,7 @@ func Graph(cfg *packages.Config, patterns ...string) ([]*PackageSpec, error) {
 type program struct {
 	fset     *token.FileSet
 	packages map[string]*types.Package
+	exports  *memcache.Cache
 }
 
 type Stats struct {
@@ -143,22 +153,29 @@ type Stats struct {
 
 // Load loads the package described in spec. Imports will be loaded
 // from export data, while the package itself will be loaded from
-// source.
+// source. Imports whose export data is out of date (see Refresh) will
+// be loaded from source, too.
+//
+// Export data is read through exports, which is keyed by the
+// packages' hashes and may be shared by concurrent calls of Load. It
+// may be nil.
 //
 // An error will only be returned for system failures, such as failure
 // to read export data from disk. Syntax and type errors, among
 // others, will only populate the returned package's Errors field.
-func Load(spec *PackageSpec) (*Package, Stats, error) {
+func Load(spec *PackageSpec, exports *memcache.Cache) (*Package, Stats, error) {
 	prog := &program{
 		fset:     token.NewFileSet(),
 		packages: map[string]*types.Package{},
+		exports:  exports,
 	}
 
 	stats := Stats{
 		Export: map[*PackageSpec]time.Duration{},
 	}
+	imports, stale := dependencies(spec)
 	var b []byte
-	for _, imp := range spec.Imports {
+	for _, imp := range imports {
 		if imp.PkgPath == "unsafe" {
 			continue
 		}
@@ -170,6 +187,18 @@ func Load(spec *PackageSpec) (*Package, Stats, error) {
 			return nil, stats, err
 		}
 	}
+	for _, dep := range stale {
+		t := time.Now()
+		pkg, err := prog.loadFromSource(dep)
+		stats.Export[dep] = time.Since(t)
+		if err != nil {
+			return nil, stats, err
+		}
+		if len(pkg.Errors) > 0 {
+			return nil, stats, fmt.Errorf("dependency %q has errors", dep.ID)
+		}
+		prog.packages[dep.PkgPath] = pkg.Types
+	}
 	t := time.Now()
 	pkg, err := prog.loadFromSource(spec)
 	if err == errMaxFileSize {
@@ -179,24 +208,65 @@ func Load(spec *PackageSpec) (*Package, Stats, error) {
 	return pkg, stats, err
 }
 
+// dependencies returns the packages that have to be loaded before
+// spec can be loaded from source. Imports are loaded from export data,
+// stale packages from source, in the order in which they are returned.
+//
+// Usually, that's just spec's imports. Stale imports, however, have to
+// be loaded from source, together with their own stale dependencies
+// and the imports of those.
+func dependencies(spec *PackageSpec) (imports []*PackageSpec, stale []*PackageSpec) {
+	seen := map[*PackageSpec]bool{}
+	var visit func(spec *PackageSpec)
+	visit = func(spec *PackageSpec) {
+		for _, imp := range spec.Imports {
+			if seen[imp] {
+				continue
+			}
+			seen[imp] = true
+			if imp.stale {
+				visit(imp)
+				stale = append(stale, imp)
+			} else {
+				imports = append(imports, imp)
+			}
+		}
+	}
+	visit(spec)
+	return imports, stale
+}
+
 // loadFromExport loads a package from export data.
 func (prog *program) loadFromExport(spec *PackageSpec, b []byte) (*Package, []byte, error) {
 	// log.Printf("Loading package %s from export", spec)
 	if spec.ExportFile == "" {
 		return nil, b, fmt.Errorf("no export data for %q", spec.ID)
 	}
-	f, err := os.Open(spec.ExportFile)
-	if err != nil {
-		return nil, b, err
+	var data []byte
+	if prog.exports == nil {
+		var err error
+		b, err = readExportData(spec.ExportFile, b)
+		if err != nil {
+			return nil, b, err
+		}
+		data = b
+	} else {
+		// Cached export data is shared, so we can neither read into
+		// b nor reuse the cached data as a buffer.
+		v, err := prog.exports.Get(spec.Hash, func() (interface{}, int64, error) {
+			data, err := readExportData(spec.ExportFile, nil)
+			return data, int64(cap(data)), err
+		})
+		if err != nil {
+			return nil, b, err
+		}
+		data = v.([]byte)
 	}
-	defer f.Close()
-
-	b, err = gcimporter.GetExportData(f, b)
-	if err != nil {
-		return nil, b, err
+	if len(data) == 0 {
+		return nil, b, fmt.Errorf("empty export data for %q", spec.ID)
 	}
 
-	_, tpkg, err := gcimporter.IImportData(prog.fset, prog.packages, b[1:], spec.PkgPath)
+	_, tpkg, err := gcimporter.IImportData(prog.fset, prog.packages, data[1:], spec.PkgPath)
 	if err != nil {
 		return nil, b, err
 	}
@@ -211,6 +281,17 @@ func (prog *program) loadFromExport(spec *PackageSpec, b []byte) (*Package, []by
 	return pkg, b, nil
 }
 
+// readExportData reads the export data from the named file, reusing b
+// if it is large enough.
+func readExportData(name string, b []byte) ([]byte, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return b, err
+	}
+	defer f.Close()
+	return gcimporter.GetExportData(f, b)
+}
+
 // loadFromSource loads a package from source. All of its dependencies
 // must have been loaded already.
 func (prog *program) loadFromSource(spec *PackageSpec) (*Package, error) {
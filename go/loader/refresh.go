@@ -0,0 +1,181 @@
+package loader
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+
+	"honnef.co/go/tools/internal/cache"
+)
+
+// ErrGraphChanged is returned by Refresh if changes can't be applied
+// to a package graph without loading it again.
+var ErrGraphChanged = errors.New("package graph has changed")
+
+// Refresh updates a package graph returned by Graph after Go files of
+// the packages in changed have been modified. It computes new hashes
+// for the changed packages and all packages that depend on them, and
+// returns these affected packages, dependencies first.
+//
+// Unlike Graph, Refresh doesn't run go list, which means that the
+// export data of affected packages will be out of date. Load loads
+// such packages from source instead.
+//
+// Changes to files may require updating the graph itself, for example
+// because imports were added or removed. In that case, Refresh
+// returns ErrGraphChanged, and the graph has to be loaded again.
+func Refresh(graph []*PackageSpec, changed []*PackageSpec) ([]*PackageSpec, error) {
+	for _, spec := range changed {
+		if err := checkFiles(spec); err != nil {
+			return nil, err
+		}
+	}
+
+	// Sort the graph topologically and find each package's
+	// dependents.
+	var order []*PackageSpec
+	dependents := map[*PackageSpec][]*PackageSpec{}
+	seen := map[*PackageSpec]bool{}
+	var visit func(spec *PackageSpec)
+	visit = func(spec *PackageSpec) {
+		if seen[spec] {
+			return
+		}
+		seen[spec] = true
+		for _, imp := range spec.Imports {
+			dependents[imp] = append(dependents[imp], spec)
+			visit(imp)
+		}
+		order = append(order, spec)
+	}
+	for _, spec := range graph {
+		visit(spec)
+	}
+
+	affected := map[*PackageSpec]bool{}
+	var mark func(spec *PackageSpec)
+	mark = func(spec *PackageSpec) {
+		if affected[spec] {
+			return
+		}
+		affected[spec] = true
+		for _, dep := range dependents[spec] {
+			mark(dep)
+		}
+	}
+	for _, spec := range changed {
+		mark(spec)
+	}
+
+	var out []*PackageSpec
+	for _, spec := range order {
+		if !affected[spec] {
+			continue
+		}
+		if !spec.stale {
+			spec.stale = true
+			spec.graphHash = spec.Hash
+		}
+		h, err := computeStaleHash(spec)
+		if err != nil {
+			return nil, err
+		}
+		spec.Hash = h
+		out = append(out, spec)
+	}
+	return out, nil
+}
+
+// checkFiles checks that the package's Go files still make up the same
+// package, with the same imports, as when the graph was loaded.
+func checkFiles(spec *PackageSpec) error {
+	if len(spec.Errors) > 0 {
+		// go list may have fixed the errors by now
+		return ErrGraphChanged
+	}
+	if len(spec.GoFiles) != len(spec.CompiledGoFiles) {
+		return ErrGraphChanged
+	}
+	for i, f := range spec.GoFiles {
+		if spec.CompiledGoFiles[i] != f {
+			// cgo has to preprocess the package again
+			return ErrGraphChanged
+		}
+	}
+
+	fset := token.NewFileSet()
+	imports := map[string]bool{}
+	for _, name := range spec.GoFiles {
+		f, err := parser.ParseFile(fset, name, nil, parser.ImportsOnly)
+		if f == nil {
+			// The file couldn't be read at all, it may have been
+			// deleted.
+			return ErrGraphChanged
+		}
+		// Syntax errors will be reported when loading the package.
+		// The file still contains the imports that could be parsed.
+		if err == nil && f.Name.Name != spec.Name {
+			return ErrGraphChanged
+		}
+		for _, imp := range f.Imports {
+			path, err := strconv.Unquote(imp.Path.Value)
+			if err != nil {
+				continue
+			}
+			imports[path] = true
+		}
+	}
+	if len(imports) != len(spec.Imports) {
+		return ErrGraphChanged
+	}
+	for path := range spec.Imports {
+		if !imports[path] {
+			return ErrGraphChanged
+		}
+	}
+	return nil
+}
+
+// computeStaleHash computes the hash of a stale package. The hash is
+// based on the hash computed by Graph, the current contents of the
+// package's files, and the hashes of stale imports.
+func computeStaleHash(spec *PackageSpec) (cache.ActionID, error) {
+	key := cache.NewHash("stale package " + spec.PkgPath)
+	fmt.Fprintf(key, "graph %x\n", spec.graphHash)
+
+	// We can't use cache.FileHash, which caches hashes for the
+	// lifetime of the process.
+	for _, name := range spec.CompiledGoFiles {
+		f, err := os.Open(name)
+		if err != nil {
+			return cache.ActionID{}, err
+		}
+		h := sha256.New()
+		_, err = io.Copy(h, f)
+		f.Close()
+		if err != nil {
+			return cache.ActionID{}, err
+		}
+		fmt.Fprintf(key, "file %s %x\n", name, h.Sum(nil))
+	}
+
+	imps := make([]*PackageSpec, 0, len(spec.Imports))
+	for _, imp := range spec.Imports {
+		if imp.stale {
+			imps = append(imps, imp)
+		}
+	}
+	sort.Slice(imps, func(i, j int) bool {
+		return imps[i].PkgPath < imps[j].PkgPath
+	})
+	for _, imp := range imps {
+		fmt.Fprintf(key, "import %s %x\n", imp.PkgPath, imp.Hash)
+	}
+	return key.Sum(), nil
+}
@@ -0,0 +1,100 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+// A Backend is a remote store of cache entries, such as a server
+// shared by CI workers and developer machines. A Cache with a backend
+// reads through to it when an entry is missing locally, and writes
+// all new entries through to it.
+//
+// Backends needn't be trusted to return intact data: the cache
+// verifies outputs against their output IDs before using them.
+type Backend interface {
+	// Get returns the output ID stored for the action ID, and a
+	// reader for the output's contents. If there is no such entry,
+	// the error satisfies errors.Is(err, os.ErrNotExist).
+	Get(id ActionID) (OutputID, io.ReadCloser, error)
+	// Put stores the output read from r, which has the given output
+	// ID and size, for the action ID.
+	Put(id ActionID, out OutputID, size int64, r io.Reader) error
+}
+
+// SetBackend makes the cache read and write through to b. It must be
+// called before the cache is used.
+func (c *Cache) SetBackend(b Backend) {
+	c.backend = b
+}
+
+// BackendError returns the first error that occurred while using the
+// backend, other than entries not being found. Such errors never
+// fail operations on the cache, which treats the backend as best
+// effort, but the cache stops using the backend after the first
+// one.
+func (c *Cache) BackendError() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.backendErr
+}
+
+// useBackend reports whether the cache has a backend that hasn't
+// failed yet.
+func (c *Cache) useBackend() bool {
+	return c.backend != nil && c.BackendError() == nil
+}
+
+func (c *Cache) backendFailed(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.backendErr == nil {
+		c.backendErr = err
+	}
+}
+
+// fetch copies the entry for the action ID from the backend into the
+// local cache.
+func (c *Cache) fetch(id ActionID) (Entry, error) {
+	missing := func(reason error) (Entry, error) {
+		return Entry{}, &entryNotFoundError{Err: reason}
+	}
+	out, r, err := c.backend.Get(id)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			c.backendFailed(err)
+		}
+		return missing(err)
+	}
+	defer r.Close()
+
+	// Buffer the output so that we can verify it before it enters
+	// the cache.
+	f, err := ioutil.TempFile("", "staticcheck-cache")
+	if err != nil {
+		return missing(err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(f, h), r); err != nil {
+		c.backendFailed(err)
+		return missing(err)
+	}
+	var got OutputID
+	h.Sum(got[:0])
+	if got != out {
+		err := fmt.Errorf("output of remote entry %x doesn't match its output ID", id)
+		c.backendFailed(err)
+		return missing(err)
+	}
+
+	if _, _, err := c.putLocal(id, f, false); err != nil {
+		return missing(err)
+	}
+	return c.get(id)
+}
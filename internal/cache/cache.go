@@ -20,6 +20,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"honnef.co/go/tools/internal/renameio"
@@ -37,6 +38,13 @@ type OutputID [HashSize]byte
 type Cache struct {
 	dir string
 	now func() time.Time
+
+	// optional remote store that the cache reads and writes through to
+	backend Backend
+
+	mu sync.Mutex
+	// the first error encountered while using the backend
+	backendErr error
 }
 
 // Open opens and returns the cache in the given directory.
@@ -143,7 +151,11 @@ func (c *Cache) Get(id ActionID) (Entry, error) {
 	if verify {
 		return Entry{}, &entryNotFoundError{Err: errVerifyMode}
 	}
-	return c.get(id)
+	entry, err := c.get(id)
+	if err != nil && c.useBackend() {
+		return c.fetch(id)
+	}
+	return entry, err
 }
 
 type Entry struct {
@@ -154,6 +166,17 @@ type Entry struct {
 
 // get is Get but does not respect verify mode, so that Put can use it.
 func (c *Cache) get(id ActionID) (Entry, error) {
+	entry, err := c.readIndexEntry(id)
+	if err != nil {
+		return Entry{}, err
+	}
+	c.used(c.fileName(id, "a"))
+	return entry, nil
+}
+
+// readIndexEntry reads the index entry of the action ID without
+// marking it as used.
+func (c *Cache) readIndexEntry(id ActionID) (Entry, error) {
 	missing := func(reason error) (Entry, error) {
 		return Entry{}, &entryNotFoundError{Err: reason}
 	}
@@ -211,8 +234,6 @@ func (c *Cache) get(id ActionID) (Entry, error) {
 		return missing(errors.New("negative timestamp"))
 	}
 
-	c.used(c.fileName(id, "a"))
-
 	return Entry{buf, size, time.Unix(0, tm)}, nil
 }
 
@@ -416,6 +437,22 @@ func (c *Cache) PutNoVerify(id ActionID, file io.ReadSeeker) (OutputID, int64, e
 }
 
 func (c *Cache) put(id ActionID, file io.ReadSeeker, allowVerify bool) (OutputID, int64, error) {
+	out, size, err := c.putLocal(id, file, allowVerify)
+	if err != nil || !c.useBackend() {
+		return out, size, err
+	}
+	// Write through to the backend. The backend is best effort;
+	// failing to upload an entry doesn't fail the Put.
+	if _, err := file.Seek(0, 0); err != nil {
+		c.backendFailed(err)
+	} else if err := c.backend.Put(id, out, size, io.LimitReader(file, size)); err != nil {
+		c.backendFailed(err)
+	}
+	return out, size, nil
+}
+
+// putLocal is put without writing through to the backend.
+func (c *Cache) putLocal(id ActionID, file io.ReadSeeker, allowVerify bool) (OutputID, int64, error) {
 	// Compute output ID.
 	h := sha256.New()
 	if _, err := file.Seek(0, 0); err != nil {
@@ -10,6 +10,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 )
 
@@ -46,6 +47,12 @@ func initDefaultCache() {
 	if err != nil {
 		log.Fatalf("failed to initialize build cache at %s: %s\n", dir, err)
 	}
+	if url := os.Getenv("STATICCHECK_CACHE_URL"); url != "" {
+		if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+			log.Fatalf("STATICCHECK_CACHE_URL is not an HTTP or HTTPS URL: %s\n", url)
+		}
+		c.SetBackend(NewHTTPBackend(url))
+	}
 	defaultCache = c
 }
 
@@ -0,0 +1,125 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// An HTTPBackend is a Backend that stores entries on an HTTP server.
+//
+// The server has to support GET and PUT requests of arbitrary paths
+// below the base URL. Outputs are stored content-addressed at
+// cas/<output ID>, and action IDs map to output IDs via ac/<action
+// ID>, which contains the hex-encoded output ID. Outputs are always
+// uploaded before the entries referring to them.
+type HTTPBackend struct {
+	// Base URL of the server, without a trailing slash
+	URL string
+	// Client to make requests with. If nil, http.DefaultClient is
+	// used.
+	Client *http.Client
+}
+
+// httpTimeout bounds the requests of backends created by
+// NewHTTPBackend, so that an unresponsive server can't stall a run.
+const httpTimeout = time.Minute
+
+// NewHTTPBackend returns a backend that stores entries below the base
+// URL.
+func NewHTTPBackend(url string) *HTTPBackend {
+	return &HTTPBackend{
+		URL:    strings.TrimSuffix(url, "/"),
+		Client: &http.Client{Timeout: httpTimeout},
+	}
+}
+
+func (b *HTTPBackend) client() *http.Client {
+	if b.Client != nil {
+		return b.Client
+	}
+	return http.DefaultClient
+}
+
+func (b *HTTPBackend) get(path string) (io.ReadCloser, error) {
+	resp, err := b.client().Get(b.URL + "/" + path)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Body, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, fmt.Errorf("GET %s: %w", path, os.ErrNotExist)
+	default:
+		resp.Body.Close()
+		return nil, fmt.Errorf("GET %s: unexpected status %s", path, resp.Status)
+	}
+}
+
+func (b *HTTPBackend) put(path string, size int64, r io.Reader) error {
+	req, err := http.NewRequest(http.MethodPut, b.URL+"/"+path, r)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	if size == 0 {
+		// Without a body, a zero ContentLength means "unknown"
+		req.Body = http.NoBody
+	}
+	resp, err := b.client().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("PUT %s: unexpected status %s", path, resp.Status)
+	}
+	return nil
+}
+
+// Get implements Backend.
+func (b *HTTPBackend) Get(id ActionID) (OutputID, io.ReadCloser, error) {
+	r, err := b.get(fmt.Sprintf("ac/%x", id))
+	if err != nil {
+		return OutputID{}, nil, err
+	}
+	// The entry is a hex-encoded hash, optionally followed by a
+	// newline; don't read arbitrarily large bodies.
+	data, err := ioutil.ReadAll(io.LimitReader(r, hexSize+2))
+	r.Close()
+	if err != nil {
+		return OutputID{}, nil, err
+	}
+	data = bytes.TrimSpace(data)
+	var out OutputID
+	if len(data) != hexSize {
+		return OutputID{}, nil, fmt.Errorf("invalid entry for action %x", id)
+	}
+	if _, err := hex.Decode(out[:], data); err != nil {
+		return OutputID{}, nil, fmt.Errorf("invalid entry for action %x: %s", id, err)
+	}
+
+	r, err = b.get(fmt.Sprintf("cas/%x", out))
+	if err != nil {
+		return OutputID{}, nil, err
+	}
+	return out, r, nil
+}
+
+// Put implements Backend.
+func (b *HTTPBackend) Put(id ActionID, out OutputID, size int64, r io.Reader) error {
+	if err := b.put(fmt.Sprintf("cas/%x", out), size, r); err != nil {
+		return err
+	}
+	entry := fmt.Sprintf("%x\n", out)
+	return b.put(fmt.Sprintf("ac/%x", id), int64(len(entry)), strings.NewReader(entry))
+}
@@ -0,0 +1,158 @@
+package cache
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// testServer is a minimal stand-in for an HTTP cache server.
+type testServer struct {
+	mu    sync.Mutex
+	blobs map[string][]byte
+}
+
+func (s *testServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch r.Method {
+	case http.MethodGet:
+		data, ok := s.blobs[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(data)
+	case http.MethodPut:
+		data, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.blobs[r.URL.Path] = data
+		w.WriteHeader(http.StatusCreated)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func openTestCache(t *testing.T, root, url string) *Cache {
+	dir, err := ioutil.TempDir(root, "c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetBackend(NewHTTPBackend(url))
+	return c
+}
+
+func TestHTTPBackend(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cachetest-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	srv := &testServer{blobs: map[string][]byte{}}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	// Entries are written through to the server...
+	c1 := openTestCache(t, dir, ts.URL+"/")
+	data := []byte("some facts")
+	if err := c1.PutBytes(dummyID(1), data); err != nil {
+		t.Fatal(err)
+	}
+	if err := c1.BackendError(); err != nil {
+		t.Fatal(err)
+	}
+	if len(srv.blobs) != 2 {
+		t.Fatalf("server has %d blobs, want 2", len(srv.blobs))
+	}
+
+	// ...and read through by caches that don't have them.
+	c2 := openTestCache(t, dir, ts.URL)
+	got, _, err := c2.GetBytes(dummyID(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("got %q, want %q", got, data)
+	}
+	// The entry is now available locally.
+	c2.SetBackend(nil)
+	if _, _, err := c2.GetFile(dummyID(1)); err != nil {
+		t.Errorf("entry wasn't stored locally: %v", err)
+	}
+
+	// Entries that don't exist are misses, not errors.
+	c3 := openTestCache(t, dir, ts.URL)
+	if _, err := c3.Get(dummyID(2)); err == nil {
+		t.Error("got entry for unknown action")
+	}
+	if err := c3.BackendError(); err != nil {
+		t.Errorf("missing entry caused backend error: %v", err)
+	}
+
+	// Outputs that don't match their output IDs are rejected.
+	srv.mu.Lock()
+	for path := range srv.blobs {
+		if strings.HasPrefix(path, "/cas/") {
+			srv.blobs[path] = []byte("corrupted")
+		}
+	}
+	srv.mu.Unlock()
+	if _, err := c3.Get(dummyID(1)); err == nil {
+		t.Error("got entry with corrupted output")
+	}
+	if err := c3.BackendError(); err == nil || !strings.Contains(err.Error(), "doesn't match") {
+		t.Errorf("got backend error %v, want mismatched output", err)
+	}
+	if _, err := c3.get(dummyID(1)); err == nil {
+		t.Error("corrupted entry was stored locally")
+	}
+}
+
+func TestHTTPBackendError(t *testing.T) {
+	var requests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		http.Error(w, "read-only", http.StatusForbidden)
+	}))
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "cachetest-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Failing to upload doesn't fail Put.
+	c := openTestCache(t, dir, ts.URL)
+	if err := c.PutBytes(dummyID(1), []byte("data")); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.BackendError(); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("got backend error %v, want 403", err)
+	}
+	if data, _, err := c.GetBytes(dummyID(1)); err != nil || string(data) != "data" {
+		t.Errorf("GetBytes = %q, %v, want local entry", data, err)
+	}
+
+	// After the first error, the backend isn't used anymore.
+	n := atomic.LoadInt32(&requests)
+	c.PutBytes(dummyID(2), []byte("more data"))
+	c.Get(dummyID(3))
+	if m := atomic.LoadInt32(&requests); m != n {
+		t.Errorf("made %d more requests after the backend failed", m-n)
+	}
+}
@@ -0,0 +1,273 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Dir returns the directory of the cache.
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// cacheFile is an index entry (xxxx-a) or output (xxxx-d) file.
+type cacheFile struct {
+	path string
+	info os.FileInfo
+}
+
+func (f cacheFile) isEntry() bool { return strings.HasSuffix(f.path, "-a") }
+
+// hash returns the action or output ID encoded in the file's name.
+func (f cacheFile) hash() ([HashSize]byte, bool) {
+	var id [HashSize]byte
+	name := filepath.Base(f.path)
+	if len(name) != hexSize+2 {
+		return id, false
+	}
+	_, err := hex.Decode(id[:], []byte(name[:hexSize]))
+	return id, err == nil
+}
+
+// files returns all index entries and outputs in the cache.
+func (c *Cache) files() ([]cacheFile, error) {
+	var out []cacheFile
+	for i := 0; i < 256; i++ {
+		subdir := filepath.Join(c.dir, fmt.Sprintf("%02x", i))
+		f, err := os.Open(subdir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		infos, err := f.Readdir(-1)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+		for _, info := range infos {
+			// Only consider cache entries (xxxx-a and xxxx-d).
+			name := info.Name()
+			if !strings.HasSuffix(name, "-a") && !strings.HasSuffix(name, "-d") {
+				continue
+			}
+			out = append(out, cacheFile{filepath.Join(subdir, name), info})
+		}
+	}
+	return out, nil
+}
+
+// An AgeBucket is a bucket of the histogram of how long ago files in
+// the cache have last been used.
+type AgeBucket struct {
+	// Files in the bucket have last been used less than MaxAge ago.
+	// MaxAge is zero for the last bucket, which contains all older
+	// files.
+	MaxAge time.Duration
+	Files  int
+	Size   int64
+}
+
+// Usage describes the contents of a cache.
+type Usage struct {
+	// Number of index entries
+	Entries int
+	// Number of outputs
+	Outputs int
+	// Total size of entries and outputs
+	Size int64
+	Ages []AgeBucket
+}
+
+// ageBuckets are the upper bounds of the buckets in Usage.Ages.
+var ageBuckets = []time.Duration{
+	time.Hour,
+	24 * time.Hour,
+	7 * 24 * time.Hour,
+	30 * 24 * time.Hour,
+	0,
+}
+
+// Usage computes the cache's usage. Times of last use are only
+// accurate to an hour; see mtimeInterval.
+func (c *Cache) Usage() (Usage, error) {
+	files, err := c.files()
+	if err != nil {
+		return Usage{}, err
+	}
+	u := Usage{Ages: make([]AgeBucket, len(ageBuckets))}
+	for i, d := range ageBuckets {
+		u.Ages[i].MaxAge = d
+	}
+	now := c.now()
+	for _, f := range files {
+		if f.isEntry() {
+			u.Entries++
+		} else {
+			u.Outputs++
+		}
+		size := f.info.Size()
+		u.Size += size
+		age := now.Sub(f.info.ModTime())
+		for i := range u.Ages {
+			b := &u.Ages[i]
+			if b.MaxAge == 0 || age < b.MaxAge {
+				b.Files++
+				b.Size += size
+				break
+			}
+		}
+	}
+	return u, nil
+}
+
+// TrimTo removes the files that haven't been used in maxAge, and then
+// the least recently used files until the cache is no larger than
+// maxSize. Zero values mean no limit. It returns the number and total
+// size of the removed files.
+//
+// Unlike Trim, TrimTo always scans the cache.
+func (c *Cache) TrimTo(maxSize int64, maxAge time.Duration) (int, int64, error) {
+	files, err := c.files()
+	if err != nil {
+		return 0, 0, err
+	}
+	// Oldest files first
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].info.ModTime().Before(files[j].info.ModTime())
+	})
+	var total int64
+	for _, f := range files {
+		total += f.info.Size()
+	}
+
+	var n int
+	var freed int64
+	// Like Trim, we subtract an additional mtimeInterval to account
+	// for the imprecision of our "last used" mtimes.
+	cutoff := c.now().Add(-maxAge - mtimeInterval)
+	for _, f := range files {
+		tooOld := maxAge > 0 && f.info.ModTime().Before(cutoff)
+		tooLarge := maxSize > 0 && total-freed > maxSize
+		if !tooOld && !tooLarge {
+			break
+		}
+		if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+			return n, freed, err
+		}
+		n++
+		freed += f.info.Size()
+	}
+	return n, freed, nil
+}
+
+// Clean removes all entries and outputs from the cache. It returns
+// the number and total size of the removed files.
+func (c *Cache) Clean() (int, int64, error) {
+	files, err := c.files()
+	if err != nil {
+		return 0, 0, err
+	}
+	var n int
+	var freed int64
+	for _, f := range files {
+		if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+			return n, freed, err
+		}
+		n++
+		freed += f.info.Size()
+	}
+	os.Remove(filepath.Join(c.dir, "trim.txt"))
+	return n, freed, nil
+}
+
+// Verify checks the integrity of the cache. It re-hashes all outputs
+// and removes the ones that don't match their output IDs, as well as
+// index entries that are malformed or that refer to missing or
+// incomplete outputs. It returns the number of checked and removed
+// files.
+func (c *Cache) Verify() (checked, removed int, err error) {
+	files, err := c.files()
+	if err != nil {
+		return 0, 0, err
+	}
+	remove := func(f cacheFile) error {
+		if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		removed++
+		return nil
+	}
+
+	// Check outputs first, so that entries referring to corrupt
+	// outputs get removed, too.
+	for _, f := range files {
+		if f.isEntry() {
+			continue
+		}
+		checked++
+		want, ok := f.hash()
+		if !ok {
+			if err := remove(f); err != nil {
+				return checked, removed, err
+			}
+			continue
+		}
+		got, err := hashFile(f.path)
+		if err != nil {
+			return checked, removed, err
+		}
+		if got != want {
+			if err := remove(f); err != nil {
+				return checked, removed, err
+			}
+		}
+	}
+	for _, f := range files {
+		if !f.isEntry() {
+			continue
+		}
+		checked++
+		id, ok := f.hash()
+		var entry Entry
+		if ok {
+			entry, err = c.readIndexEntry(id)
+			ok = err == nil
+		}
+		if ok {
+			info, err := os.Stat(c.fileName(entry.OutputID, "d"))
+			ok = err == nil && info.Size() == entry.Size
+		}
+		if !ok {
+			if err := remove(f); err != nil {
+				return checked, removed, err
+			}
+		}
+	}
+	return checked, removed, nil
+}
+
+// hashFile returns the SHA256 hash of the file's contents. Unlike
+// FileHash, it doesn't use the in-process cache of hashes.
+func hashFile(name string) ([HashSize]byte, error) {
+	var out [HashSize]byte
+	f, err := os.Open(name)
+	if err != nil {
+		return out, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return out, err
+	}
+	h.Sum(out[:0])
+	return out, nil
+}
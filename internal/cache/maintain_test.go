@@ -0,0 +1,102 @@
+package cache
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestMaintenance(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cachetest-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	now := time.Unix(1000000000, 0)
+	c.now = func() time.Time { return now }
+
+	// Three entries, last used 10 days, 2 days and 1 minute ago.
+	ages := []time.Duration{10 * 24 * time.Hour, 2 * 24 * time.Hour, time.Minute}
+	for i, age := range ages {
+		id := ActionID(dummyID(i))
+		if err := c.PutBytes(id, []byte(fmt.Sprintf("output %d", i))); err != nil {
+			t.Fatal(err)
+		}
+		entry, err := c.Get(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mtime := now.Add(-age)
+		os.Chtimes(c.fileName(id, "a"), mtime, mtime)
+		os.Chtimes(c.fileName(entry.OutputID, "d"), mtime, mtime)
+	}
+
+	u, err := c.Usage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Entries != 3 || u.Outputs != 3 {
+		t.Errorf("got %d entries and %d outputs, want 3 and 3", u.Entries, u.Outputs)
+	}
+	var files []int
+	for _, b := range u.Ages {
+		files = append(files, b.Files)
+	}
+	if want := []int{2, 0, 2, 2, 0}; fmt.Sprint(files) != fmt.Sprint(want) {
+		t.Errorf("got age histogram %v, want %v", files, want)
+	}
+
+	// Trimming by age removes the oldest entry.
+	if n, _, err := c.TrimTo(0, 5*24*time.Hour); err != nil || n != 2 {
+		t.Errorf("TrimTo(age) removed %d files (err %v), want 2", n, err)
+	}
+	if _, err := c.Get(dummyID(0)); err == nil {
+		t.Error("TrimTo(age) didn't remove the oldest entry")
+	}
+
+	// Trimming by size removes the least recently used entries.
+	entry, _ := c.Get(dummyID(2))
+	a, _ := os.Stat(c.fileName(dummyID(2), "a"))
+	if n, _, err := c.TrimTo(a.Size()+entry.Size, 0); err != nil || n != 2 {
+		t.Errorf("TrimTo(size) removed %d files (err %v), want 2", n, err)
+	}
+	if _, _, err := c.GetBytes(dummyID(1)); err == nil {
+		t.Error("TrimTo(size) didn't remove the least recently used entry")
+	}
+	if _, _, err := c.GetBytes(dummyID(2)); err != nil {
+		t.Errorf("TrimTo(size) removed the most recently used entry: %v", err)
+	}
+
+	// Verify removes corrupt outputs and the entries referring to
+	// them, as well as entries whose outputs are missing.
+	if err := ioutil.WriteFile(c.fileName(entry.OutputID, "d"), []byte("corrupt!"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	c.PutBytes(dummyID(4), []byte("gone"))
+	entry, _ = c.Get(dummyID(4))
+	os.Remove(c.fileName(entry.OutputID, "d"))
+	if checked, removed, err := c.Verify(); err != nil || checked != 3 || removed != 3 {
+		t.Errorf("Verify() = %d, %d, %v, want 3, 3, nil", checked, removed, err)
+	}
+	if u, _ := c.Usage(); u.Entries != 0 || u.Outputs != 0 {
+		t.Errorf("got %d entries and %d outputs after Verify, want none", u.Entries, u.Outputs)
+	}
+
+	c.PutBytes(dummyID(3), []byte("data"))
+	if checked, removed, err := c.Verify(); err != nil || checked != 2 || removed != 0 {
+		t.Errorf("Verify() = %d, %d, %v, want 2, 0, nil", checked, removed, err)
+	}
+	if n, _, err := c.Clean(); err != nil || n != 2 {
+		t.Errorf("Clean removed %d files (err %v), want 2", n, err)
+	}
+	if _, err := c.Get(dummyID(3)); err == nil {
+		t.Error("Clean didn't remove all entries")
+	}
+}
@@ -0,0 +1,221 @@
+// Package diff computes line-based differences between two texts and
+// renders them as unified diffs.
+package diff
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// An OpKind describes the kind of an Op.
+type OpKind uint8
+
+const (
+	Equal OpKind = iota
+	Delete
+	Insert
+)
+
+// An Op describes a single line that is either shared by both texts,
+// deleted from the old text or inserted into the new text.
+type Op struct {
+	Kind OpKind
+	Line []byte
+}
+
+// SplitLines splits b into lines, each line retaining its trailing
+// newline. The last line may lack a newline.
+func SplitLines(b []byte) [][]byte {
+	var lines [][]byte
+	for len(b) > 0 {
+		idx := bytes.IndexByte(b, '\n')
+		if idx == -1 {
+			lines = append(lines, b)
+			break
+		}
+		lines = append(lines, b[:idx+1])
+		b = b[idx+1:]
+	}
+	return lines
+}
+
+// Lines computes the shortest edit script that turns the lines of a
+// into the lines of b, using Myers' O(ND) algorithm.
+func Lines(a, b [][]byte) []Op {
+	n, m := len(a), len(b)
+	max := n + m
+	if max == 0 {
+		return nil
+	}
+	offset := max
+	v := make([]int, 2*max+2)
+	var trace [][]int
+
+	var d int
+outer:
+	for d = 0; d <= max; d++ {
+		vc := make([]int, len(v))
+		copy(vc, v)
+		trace = append(trace, vc)
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && bytes.Equal(a[x], b[y]) {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				break outer
+			}
+		}
+	}
+
+	// Walk the trace backwards to recover the edit script.
+	ops := make([]Op, 0, n+m)
+	x, y := n, m
+	for ; d > 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			ops = append(ops, Op{Equal, a[x]})
+		}
+		if x == prevX {
+			y--
+			ops = append(ops, Op{Insert, b[y]})
+		} else {
+			x--
+			ops = append(ops, Op{Delete, a[x]})
+		}
+	}
+	for x > 0 && y > 0 {
+		x--
+		y--
+		ops = append(ops, Op{Equal, a[x]})
+	}
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
+
+// contextLines is the number of unchanged lines shown around each
+// change in a unified diff.
+const contextLines = 3
+
+// Unified returns a unified diff of old and new. The file names are
+// used in the diff's header. It returns the empty string if the two
+// texts are identical.
+func Unified(oldName, newName string, old, new []byte) string {
+	ops := Lines(SplitLines(old), SplitLines(new))
+	changed := false
+	for _, op := range ops {
+		if op.Kind != Equal {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return ""
+	}
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "--- %s\n+++ %s\n", oldName, newName)
+
+	// oldLine and newLine track the 1-based line numbers of ops[i]
+	// in the old and new texts.
+	oldLine, newLine := make([]int, len(ops)+1), make([]int, len(ops)+1)
+	oldLine[0], newLine[0] = 1, 1
+	for i, op := range ops {
+		oldLine[i+1], newLine[i+1] = oldLine[i], newLine[i]
+		if op.Kind != Insert {
+			oldLine[i+1]++
+		}
+		if op.Kind != Delete {
+			newLine[i+1]++
+		}
+	}
+
+	for i := 0; i < len(ops); {
+		if ops[i].Kind == Equal {
+			i++
+			continue
+		}
+		// Found a change; extend the hunk for as long as changes are
+		// separated by at most 2*contextLines unchanged lines.
+		start := i - contextLines
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for j := i; j < len(ops); j++ {
+			if ops[j].Kind != Equal {
+				end = j + 1
+				continue
+			}
+			if j-end >= 2*contextLines {
+				break
+			}
+		}
+		end += contextLines
+		if end > len(ops) {
+			end = len(ops)
+		}
+
+		var oldCount, newCount int
+		for _, op := range ops[start:end] {
+			if op.Kind != Insert {
+				oldCount++
+			}
+			if op.Kind != Delete {
+				newCount++
+			}
+		}
+		oldStart, newStart := oldLine[start], newLine[start]
+		if oldCount == 0 {
+			oldStart--
+		}
+		if newCount == 0 {
+			newStart--
+		}
+		fmt.Fprintf(buf, "@@ -%s +%s @@\n", hunkRange(oldStart, oldCount), hunkRange(newStart, newCount))
+		for _, op := range ops[start:end] {
+			switch op.Kind {
+			case Equal:
+				buf.WriteByte(' ')
+			case Delete:
+				buf.WriteByte('-')
+			case Insert:
+				buf.WriteByte('+')
+			}
+			buf.Write(op.Line)
+			if len(op.Line) == 0 || op.Line[len(op.Line)-1] != '\n' {
+				buf.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		i = end
+	}
+	return buf.String()
+}
+
+func hunkRange(start, count int) string {
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
@@ -0,0 +1,65 @@
+package diff
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+func TestLines(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	gen := func() []byte {
+		buf := &bytes.Buffer{}
+		n := rng.Intn(20)
+		for i := 0; i < n; i++ {
+			buf.WriteByte(byte('a' + rng.Intn(4)))
+			buf.WriteByte('\n')
+		}
+		return buf.Bytes()
+	}
+	for i := 0; i < 1000; i++ {
+		a, b := gen(), gen()
+		var gotA, gotB []byte
+		for _, op := range Lines(SplitLines(a), SplitLines(b)) {
+			if op.Kind != Insert {
+				gotA = append(gotA, op.Line...)
+			}
+			if op.Kind != Delete {
+				gotB = append(gotB, op.Line...)
+			}
+		}
+		if !bytes.Equal(gotA, a) || !bytes.Equal(gotB, b) {
+			t.Fatalf("edit script for %q -> %q doesn't reproduce inputs", a, b)
+		}
+	}
+}
+
+func TestUnified(t *testing.T) {
+	old := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\n"
+	new := "a\nb\nc\nD\ne\nf\ng\nh\ni\nj\nk\nl"
+	want := `--- a/file.go
++++ b/file.go
+@@ -1,7 +1,7 @@
+ a
+ b
+ c
+-d
++D
+ e
+ f
+ g
+@@ -9,3 +9,4 @@
+ i
+ j
+ k
++l
+\ No newline at end of file
+`
+	if got := Unified("a/file.go", "b/file.go", []byte(old), []byte(new)); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	if got := Unified("a", "b", []byte(old), []byte(old)); got != "" {
+		t.Errorf("got non-empty diff for identical inputs: %q", got)
+	}
+}
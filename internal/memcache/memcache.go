@@ -0,0 +1,177 @@
+// Package memcache implements a short-lived, size-bounded in-memory
+// cache that suppresses duplicate work.
+//
+// It is meant for data that is expensive to load and likely to be
+// needed several times in close succession, such as the export data
+// of a package that several packages that are being analyzed in
+// parallel depend on. Entries expire a fixed time after they have
+// been added, so that data doesn't stay in memory long after it has
+// stopped being useful.
+package memcache
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type entry struct {
+	key interface{}
+	// closed once the value has been loaded
+	done    chan struct{}
+	value   interface{}
+	size    int64
+	err     error
+	expires time.Time
+}
+
+// A Cache maps keys to values. A nil *Cache caches nothing, but is
+// otherwise valid to use.
+type Cache struct {
+	// Hits and misses come first to be 64-bit aligned for atomic
+	// access on 32-bit platforms.
+	hits   uint64
+	misses uint64
+
+	ttl     time.Duration
+	maxSize int64
+	now     func() time.Time
+
+	mu      sync.Mutex
+	entries map[interface{}]*entry
+	// loaded entries, in the order they were added, which is also
+	// the order in which they expire
+	order []*entry
+	size  int64
+}
+
+// New returns a cache whose entries expire after ttl, and whose
+// entries have a total size of no more than maxSize bytes.
+func New(ttl time.Duration, maxSize int64) *Cache {
+	return &Cache{
+		ttl:     ttl,
+		maxSize: maxSize,
+		now:     time.Now,
+		entries: map[interface{}]*entry{},
+	}
+}
+
+// Get returns the value for key. If the value isn't cached, Get calls
+// load, which returns the value and its approximate size in bytes.
+// Concurrent calls of Get for the same key share a single call of
+// load. Errors are returned to all waiting callers, but not cached.
+//
+// Keys must be comparable. Values are shared between callers and
+// must not be modified.
+func (c *Cache) Get(key interface{}, load func() (interface{}, int64, error)) (interface{}, error) {
+	if c == nil {
+		v, _, err := load()
+		return v, err
+	}
+
+	c.mu.Lock()
+	c.expire()
+	if e, ok := c.entries[key]; ok {
+		c.mu.Unlock()
+		atomic.AddUint64(&c.hits, 1)
+		<-e.done
+		return e.value, e.err
+	}
+	e := &entry{key: key, done: make(chan struct{})}
+	c.entries[key] = e
+	c.mu.Unlock()
+	atomic.AddUint64(&c.misses, 1)
+
+	e.value, e.size, e.err = load()
+	close(e.done)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e.err != nil || e.size > c.maxSize {
+		c.delete(e)
+	} else {
+		c.insert(e)
+	}
+	return e.value, e.err
+}
+
+// Purge removes all entries from the cache. Loads that are in
+// progress aren't affected.
+func (c *Cache) Purge() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, e := range c.order {
+		c.delete(e)
+	}
+	c.order = nil
+	c.size = 0
+}
+
+// SetMaxSize changes the maximum total size of the entries, evicting
+// the oldest entries if the cache is too large.
+func (c *Cache) SetMaxSize(maxSize int64) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.maxSize = maxSize
+	for c.size > c.maxSize {
+		c.evictOldest()
+	}
+}
+
+// Hits returns how often Get found a cached value or a load in
+// progress.
+func (c *Cache) Hits() uint64 {
+	if c == nil {
+		return 0
+	}
+	return atomic.LoadUint64(&c.hits)
+}
+
+// Misses returns how often Get had to load a value.
+func (c *Cache) Misses() uint64 {
+	if c == nil {
+		return 0
+	}
+	return atomic.LoadUint64(&c.misses)
+}
+
+// insert adds a loaded entry to the cache, evicting the oldest entries
+// if the cache grows too large. The caller must hold c.mu.
+func (c *Cache) insert(e *entry) {
+	e.expires = c.now().Add(c.ttl)
+	c.order = append(c.order, e)
+	c.size += e.size
+	for c.size > c.maxSize {
+		c.evictOldest()
+	}
+}
+
+// expire evicts expired entries. The caller must hold c.mu.
+func (c *Cache) expire() {
+	now := c.now()
+	for len(c.order) > 0 && !now.Before(c.order[0].expires) {
+		c.evictOldest()
+	}
+}
+
+func (c *Cache) evictOldest() {
+	e := c.order[0]
+	c.order[0] = nil
+	c.order = c.order[1:]
+	c.size -= e.size
+	c.delete(e)
+}
+
+// delete removes e from the map of entries, unless the key has since
+// been reused by a different entry.
+func (c *Cache) delete(e *entry) {
+	if c.entries[e.key] == e {
+		delete(c.entries, e.key)
+	}
+}
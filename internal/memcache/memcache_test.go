@@ -0,0 +1,124 @@
+package memcache
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func value(v interface{}, size int64) func() (interface{}, int64, error) {
+	return func() (interface{}, int64, error) { return v, size, nil }
+}
+
+func TestDuplicateSuppression(t *testing.T) {
+	c := New(time.Minute, 100)
+	var loads int32
+	release := make(chan struct{})
+	load := func() (interface{}, int64, error) {
+		atomic.AddInt32(&loads, 1)
+		<-release
+		return "v", 1, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, err := c.Get("k", load); v != "v" || err != nil {
+				t.Errorf("Get = %v, %v, want v, nil", v, err)
+			}
+		}()
+	}
+	// Wait for all goroutines to have called Get before letting the
+	// load finish.
+	for c.Hits()+c.Misses() < 10 {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+	if loads != 1 {
+		t.Errorf("loaded %d times, want 1", loads)
+	}
+	if c.Hits() != 9 || c.Misses() != 1 {
+		t.Errorf("got %d hits and %d misses, want 9 and 1", c.Hits(), c.Misses())
+	}
+}
+
+func TestExpiry(t *testing.T) {
+	now := time.Unix(0, 0)
+	c := New(time.Second, 100)
+	c.now = func() time.Time { return now }
+
+	c.Get("a", value(1, 1))
+	now = now.Add(500 * time.Millisecond)
+	c.Get("b", value(2, 1))
+	if v, _ := c.Get("a", value(10, 1)); v != 1 {
+		t.Errorf("got %v for a, want cached 1", v)
+	}
+	now = now.Add(500 * time.Millisecond)
+	if v, _ := c.Get("a", value(10, 1)); v != 10 {
+		t.Errorf("got %v for a, want reloaded 10", v)
+	}
+	if v, _ := c.Get("b", value(20, 1)); v != 2 {
+		t.Errorf("got %v for b, want cached 2", v)
+	}
+}
+
+func TestSizeBound(t *testing.T) {
+	c := New(time.Minute, 10)
+	c.Get("a", value(1, 4))
+	c.Get("b", value(2, 4))
+	// Adding c evicts a, the oldest entry.
+	c.Get("c", value(3, 4))
+	if v, _ := c.Get("a", value(10, 4)); v != 10 {
+		t.Errorf("got %v for a, want reloaded 10", v)
+	}
+	if c.size > 10 {
+		t.Errorf("cache has size %d, want at most 10", c.size)
+	}
+	// Values larger than the cache aren't cached.
+	c.Get("huge", value(4, 11))
+	if v, _ := c.Get("huge", value(40, 11)); v != 40 {
+		t.Errorf("got %v for huge, want reloaded 40", v)
+	}
+
+	// Shrinking the cache evicts c, the oldest entry, and keeps a.
+	c.SetMaxSize(5)
+	if len(c.entries) != 1 || c.size > 5 {
+		t.Errorf("cache has %d entries of size %d, want 1 of at most 5", len(c.entries), c.size)
+	}
+	if v, _ := c.Get("a", value(100, 4)); v != 10 {
+		t.Errorf("got %v for a, want cached 10", v)
+	}
+
+	c.Purge()
+	if c.size != 0 || len(c.entries) != 0 {
+		t.Errorf("Purge left %d entries of size %d", len(c.entries), c.size)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	c := New(time.Minute, 10)
+	errLoad := errors.New("failed")
+	if _, err := c.Get("a", func() (interface{}, int64, error) { return nil, 0, errLoad }); err != errLoad {
+		t.Errorf("got error %v, want %v", err, errLoad)
+	}
+	if v, err := c.Get("a", value(1, 1)); v != 1 || err != nil {
+		t.Errorf("Get = %v, %v, want 1, nil; errors must not be cached", v, err)
+	}
+}
+
+func TestNil(t *testing.T) {
+	var c *Cache
+	if v, _ := c.Get("a", value(2, 1)); v != 2 {
+		t.Errorf("got %v, want 2", v)
+	}
+	c.Purge()
+	c.SetMaxSize(1)
+	if c.Hits() != 0 || c.Misses() != 0 {
+		t.Error("nil cache has hits or misses")
+	}
+}
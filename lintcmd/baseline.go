@@ -0,0 +1,195 @@
+package lintcmd
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"honnef.co/go/tools/internal/renameio"
+	"honnef.co/go/tools/lintcmd/runner"
+)
+
+// A baselineEntry is the fingerprint of a known problem. It
+// deliberately doesn't include line numbers, so that entries survive
+// unrelated changes to the surrounding code.
+type baselineEntry struct {
+	Check   string `json:"check"`
+	Package string `json:"package"`
+	File    string `json:"file"`
+	Message string `json:"message"`
+	// Hash of the source line the problem was reported on, ignoring
+	// leading and trailing white space
+	Line string `json:"line"`
+}
+
+// A baseline is a set of known problems that should not be reported.
+// Baselines are stored as a stream of JSON objects, one per line.
+type baseline struct {
+	path    string
+	entries []baselineEntry
+}
+
+// lineHasher computes the hashes of source lines, caching the
+// contents of files.
+type lineHasher struct {
+	files map[string][][]byte
+}
+
+func (lh *lineHasher) hash(pos token.Position) string {
+	if lh.files == nil {
+		lh.files = map[string][][]byte{}
+	}
+	lines, ok := lh.files[pos.Filename]
+	if !ok {
+		if b, err := ioutil.ReadFile(pos.Filename); err == nil {
+			lines = bytes.Split(b, []byte("\n"))
+		}
+		lh.files[pos.Filename] = lines
+	}
+	var line []byte
+	if pos.Line >= 1 && pos.Line <= len(lines) {
+		line = bytes.TrimSpace(lines[pos.Line-1])
+	}
+	sum := sha256.Sum256(line)
+	return hex.EncodeToString(sum[:8])
+}
+
+// baselinable reports whether p may be recorded in a baseline. Compile
+// errors prevent analysis and can't be baselined. Neither can problems
+// that staticcheck reports about its own inputs, such as stale
+// baseline entries, unmatched linter directives and invalid
+// configuration files; they are fixed by editing those inputs.
+func baselinable(p problem) bool {
+	switch p.Category {
+	case "compile", "staticcheck", "config":
+		return false
+	}
+	return p.Severity != severityIgnored && p.Position.IsValid()
+}
+
+func fingerprint(p problem, lh *lineHasher) baselineEntry {
+	return baselineEntry{
+		Check:   p.Category,
+		Package: p.Package,
+		File:    filepath.Base(p.Position.Filename),
+		Message: p.Message,
+		Line:    lh.hash(p.Position),
+	}
+}
+
+func readBaseline(path string) (*baseline, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	b := &baseline{path: abs}
+	sc := bufio.NewScanner(f)
+	sc.Buffer(nil, 1024*1024)
+	for n := 1; sc.Scan(); n++ {
+		var e baselineEntry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("%s:%d: malformed baseline entry: %s", path, n, err)
+		}
+		b.entries = append(b.entries, e)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// writeBaseline records all baselinable problems in the file at path.
+// It returns the number of recorded problems.
+func writeBaseline(path string, ps []problem) (int, error) {
+	lh := &lineHasher{}
+	var entries []baselineEntry
+	for _, p := range ps {
+		if baselinable(p) {
+			entries = append(entries, fingerprint(p, lh))
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		ei, ej := entries[i], entries[j]
+		if ei.Package != ej.Package {
+			return ei.Package < ej.Package
+		}
+		if ei.File != ej.File {
+			return ei.File < ej.File
+		}
+		return ei.Check < ej.Check
+	})
+
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			return 0, err
+		}
+	}
+	return len(entries), renameio.WriteFile(path, buf.Bytes(), 0666)
+}
+
+// apply marks problems that match entries in the baseline as
+// baselined. Each entry can only match a single problem. Entries that
+// didn't match anything are reported as new problems, but only if
+// their package has been checked with their check enabled, as
+// described by checked.
+func (b *baseline) apply(ps []problem, checked map[string]map[string]bool) []problem {
+	unmatched := map[baselineEntry][]int{}
+	for i, e := range b.entries {
+		unmatched[e] = append(unmatched[e], i)
+	}
+
+	lh := &lineHasher{}
+	for i := range ps {
+		p := &ps[i]
+		if !baselinable(*p) {
+			continue
+		}
+		fp := fingerprint(*p, lh)
+		if idxs := unmatched[fp]; len(idxs) > 0 {
+			unmatched[fp] = idxs[1:]
+			p.Severity = severityBaselined
+		}
+	}
+
+	var stale []int
+	for e, idxs := range unmatched {
+		if e.Check == "U1000" {
+			// See filterIgnored for why we never flag U1000.
+			continue
+		}
+		if !checked[e.Package][e.Check] {
+			continue
+		}
+		stale = append(stale, idxs...)
+	}
+	sort.Ints(stale)
+	for _, idx := range stale {
+		ps = append(ps, problem{
+			Diagnostic: runner.Diagnostic{
+				Position: token.Position{
+					Filename: b.path,
+					Line:     idx + 1,
+				},
+				Message:  "this baseline entry didn't match anything; should it be removed?",
+				Category: "staticcheck",
+			},
+		})
+	}
+	return ps
+}
@@ -0,0 +1,91 @@
+package lintcmd
+
+import (
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"honnef.co/go/tools/lintcmd/runner"
+)
+
+func TestBaseline(t *testing.T) {
+	dir, err := ioutil.TempDir("", "staticcheck")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "pkg.go")
+	if err := ioutil.WriteFile(file, []byte("package pkg\n\nvar x = 1\nvar y = 2\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	prob := func(check string, line int) problem {
+		return problem{
+			Diagnostic: runner.Diagnostic{
+				Position: token.Position{Filename: file, Line: line, Column: 1},
+				Category: check,
+				Message:  "message",
+			},
+			Package: "example.com/pkg",
+		}
+	}
+
+	path := filepath.Join(dir, "baseline")
+	n, err := writeBaseline(path, []problem{prob("SA1000", 3), prob("SA1001", 4)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Fatalf("recorded %d problems, want 2", n)
+	}
+	bl, err := readBaseline(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Line 3 moves to line 4; SA1001 no longer occurs.
+	if err := ioutil.WriteFile(file, []byte("package pkg\n\n\nvar x = 1\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	checked := map[string]map[string]bool{
+		"example.com/pkg": {"SA1000": true, "SA1001": true, "SA1002": true},
+	}
+	ps := bl.apply([]problem{prob("SA1000", 4), prob("SA1002", 4)}, checked)
+	if len(ps) != 3 {
+		t.Fatalf("got %d problems, want 3", len(ps))
+	}
+	if ps[0].Severity != severityBaselined {
+		t.Errorf("moved problem wasn't baselined")
+	}
+	if ps[1].Severity == severityBaselined {
+		t.Errorf("new problem was baselined")
+	}
+	if ps[2].Position.Filename != path || ps[2].Position.Line != 2 {
+		t.Errorf("got stale entry at %s, want %s:2", ps[2].Position, path)
+	}
+
+	// Writing a new baseline, as -baseline-write does together with
+	// -baseline, doesn't record the stale entry.
+	newPath := filepath.Join(dir, "baseline.new")
+	n, err = writeBaseline(newPath, ps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Fatalf("recorded %d problems, want 2", n)
+	}
+	nbl, err := readBaseline(newPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range nbl.entries {
+		if e.Check != "SA1000" && e.Check != "SA1002" {
+			t.Errorf("unexpected entry %+v in new baseline", e)
+		}
+	}
+	if ps := nbl.apply([]problem{prob("SA1000", 4), prob("SA1002", 4)}, checked); len(ps) != 2 {
+		t.Errorf("new baseline has stale entries: got %d problems, want 2", len(ps))
+	}
+}
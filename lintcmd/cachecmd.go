@@ -0,0 +1,123 @@
+package lintcmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"honnef.co/go/tools/internal/cache"
+)
+
+// A cacheOptions holds the arguments of -cache.
+type cacheOptions struct {
+	MaxSize uint64
+	MaxAge  time.Duration
+	// Output format of the stats command, either "text" or "json"
+	Format string
+}
+
+// runCacheCommand runs one of the commands of -cache on c: stats,
+// trim, clean or verify.
+func runCacheCommand(w io.Writer, c *cache.Cache, cmd string, opt cacheOptions) error {
+	switch cmd {
+	case "stats":
+		u, err := c.Usage()
+		if err != nil {
+			return err
+		}
+		return printCacheUsage(w, c.Dir(), u, opt.Format)
+	case "trim":
+		if opt.MaxSize == 0 && opt.MaxAge == 0 {
+			return errors.New("-cache trim requires -max-size or -max-age")
+		}
+		n, size, err := c.TrimTo(int64(opt.MaxSize), opt.MaxAge)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "removed %d files (%s)\n", n, formatBytes(uint64(size)))
+		return nil
+	case "clean":
+		n, size, err := c.Clean()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "removed %d files (%s)\n", n, formatBytes(uint64(size)))
+		return nil
+	case "verify":
+		checked, removed, err := c.Verify()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "checked %d files, removed %d corrupt or incomplete files\n", checked, removed)
+		return nil
+	default:
+		return fmt.Errorf("unknown cache command %q; valid commands are stats, trim, clean and verify", cmd)
+	}
+}
+
+// ageLabel describes an age bucket of the cache's usage.
+func ageLabel(buckets []cache.AgeBucket, i int) string {
+	format := func(d time.Duration) string {
+		switch {
+		case d%(24*time.Hour) == 0:
+			return fmt.Sprintf("%dd", d/(24*time.Hour))
+		case d%time.Hour == 0:
+			return fmt.Sprintf("%dh", d/time.Hour)
+		default:
+			return d.String()
+		}
+	}
+	if b := buckets[i]; b.MaxAge != 0 {
+		return "< " + format(b.MaxAge)
+	}
+	if i == 0 {
+		return "any"
+	}
+	return ">= " + format(buckets[i-1].MaxAge)
+}
+
+func printCacheUsage(w io.Writer, dir string, u cache.Usage, format string) error {
+	switch format {
+	case "text":
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintf(tw, "Directory:\t%s\n", dir)
+		fmt.Fprintf(tw, "Entries:\t%d\n", u.Entries)
+		fmt.Fprintf(tw, "Outputs:\t%d\n", u.Outputs)
+		fmt.Fprintf(tw, "Size:\t%s\n", formatBytes(uint64(u.Size)))
+		fmt.Fprintln(tw)
+		fmt.Fprintln(tw, "LAST USED\tFILES\tSIZE")
+		for i, b := range u.Ages {
+			fmt.Fprintf(tw, "%s\t%d\t%s\n", ageLabel(u.Ages, i), b.Files, formatBytes(uint64(b.Size)))
+		}
+		return tw.Flush()
+	case "json":
+		type bucket struct {
+			// Upper bound in seconds, or zero for the last bucket
+			MaxAge int64 `json:"max_age"`
+			Files  int   `json:"files"`
+			Size   int64 `json:"size"`
+		}
+		ju := struct {
+			Directory string   `json:"directory"`
+			Entries   int      `json:"entries"`
+			Outputs   int      `json:"outputs"`
+			Size      int64    `json:"size"`
+			Ages      []bucket `json:"ages"`
+		}{
+			Directory: dir,
+			Entries:   u.Entries,
+			Outputs:   u.Outputs,
+			Size:      u.Size,
+			Ages:      make([]bucket, len(u.Ages)),
+		}
+		for i, b := range u.Ages {
+			ju.Ages[i] = bucket{int64(b.MaxAge / time.Second), b.Files, b.Size}
+		}
+		return json.NewEncoder(w).Encode(ju)
+	default:
+		return fmt.Errorf("unsupported output format %q for -cache stats", format)
+	}
+}
@@ -0,0 +1,71 @@
+package lintcmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"honnef.co/go/tools/internal/cache"
+)
+
+func TestCacheCommand(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cachetest-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	c, err := cache.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.PutBytes(cache.ActionID{1}, []byte("data")); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := runCacheCommand(buf, c, "stats", cacheOptions{Format: "json"}); err != nil {
+		t.Fatal(err)
+	}
+	var stats struct {
+		Entries int `json:"entries"`
+		Outputs int `json:"outputs"`
+		Ages    []struct {
+			MaxAge int64 `json:"max_age"`
+			Files  int   `json:"files"`
+		} `json:"ages"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &stats); err != nil {
+		t.Fatal(err)
+	}
+	if stats.Entries != 1 || stats.Outputs != 1 || stats.Ages[0].MaxAge != 3600 || stats.Ages[0].Files != 2 {
+		t.Errorf("unexpected stats: %s", buf)
+	}
+
+	buf.Reset()
+	if err := runCacheCommand(buf, c, "stats", cacheOptions{Format: "text"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Entries:    1\n", "< 1h ", ">= 30d "} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("text stats don't contain %q:\n%s", want, buf)
+		}
+	}
+
+	if err := runCacheCommand(buf, c, "trim", cacheOptions{}); err == nil {
+		t.Error("trim without limits succeeded")
+	}
+	if err := runCacheCommand(buf, c, "prune", cacheOptions{}); err == nil {
+		t.Error("unknown command succeeded")
+	}
+
+	buf.Reset()
+	if err := runCacheCommand(buf, c, "clean", cacheOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "removed 2 files") {
+		t.Errorf("unexpected output of clean: %s", buf)
+	}
+}
@@ -0,0 +1,255 @@
+package lintcmd
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/token"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"honnef.co/go/tools/unused"
+)
+
+// A changeSet describes the lines that were added or modified by a
+// change, as described by a unified diff.
+type changeSet struct {
+	// Added or modified lines, keyed by absolute file name
+	lines map[string]map[int]bool
+	// Identifiers that occurred on deleted lines
+	deletedIdents map[string]bool
+}
+
+// parseHunkHeader parses a hunk header of the form
+// '@@ -l,s +l,s @@ optional section heading'.
+func parseHunkHeader(line string) (oldCount, newStart, newCount int, err error) {
+	fields := strings.Fields(line)
+	if len(fields) < 4 || fields[0] != "@@" || fields[3] != "@@" || !strings.HasPrefix(fields[1], "-") || !strings.HasPrefix(fields[2], "+") {
+		return 0, 0, 0, fmt.Errorf("malformed hunk header %q", line)
+	}
+	parseRange := func(s string) (int, int, error) {
+		count := 1
+		if idx := strings.IndexByte(s, ','); idx >= 0 {
+			var err error
+			count, err = strconv.Atoi(s[idx+1:])
+			if err != nil {
+				return 0, 0, err
+			}
+			s = s[:idx]
+		}
+		start, err := strconv.Atoi(s)
+		return start, count, err
+	}
+	_, oldCount, err = parseRange(fields[1][1:])
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("malformed hunk header %q: %s", line, err)
+	}
+	newStart, newCount, err = parseRange(fields[2][1:])
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("malformed hunk header %q: %s", line, err)
+	}
+	return oldCount, newStart, newCount, nil
+}
+
+// diffPath extracts the file name from a '---' or '+++' line.
+func diffPath(line string) string {
+	name := line[4:]
+	if idx := strings.IndexByte(name, '\t'); idx >= 0 {
+		// diff -u appends a timestamp
+		name = name[:idx]
+	}
+	if len(name) >= 2 && name[0] == '"' {
+		if s, err := strconv.Unquote(name); err == nil {
+			name = s
+		}
+	}
+	return name
+}
+
+// parseChanges parses a unified diff, such as the output of 'git
+// diff'. Relative file names are resolved against root. The a/ and b/
+// prefixes that git uses by default are removed.
+func parseChanges(r io.Reader, root string) (*changeSet, error) {
+	cs := &changeSet{
+		lines:         map[string]map[int]bool{},
+		deletedIdents: map[string]bool{},
+	}
+
+	var (
+		oldName    string
+		newLines   map[int]bool
+		oldLeft    int
+		newLeft    int
+		newLine    int
+		lineNumber int
+	)
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 16*1024*1024)
+	for sc.Scan() {
+		lineNumber++
+		line := sc.Text()
+		if oldLeft > 0 || newLeft > 0 {
+			// Inside a hunk
+			if line == "" {
+				// Some tools strip the trailing white space of
+				// unchanged empty lines.
+				line = " "
+			}
+			switch line[0] {
+			case ' ':
+				newLine++
+				oldLeft--
+				newLeft--
+			case '+':
+				if newLines != nil {
+					newLines[newLine] = true
+				}
+				newLine++
+				newLeft--
+			case '-':
+				for _, ident := range identifiers(line[1:]) {
+					cs.deletedIdents[ident] = true
+				}
+				oldLeft--
+			case '\\':
+				// "\ No newline at end of file"
+			default:
+				return nil, fmt.Errorf("line %d: unexpected line in hunk: %q", lineNumber, line)
+			}
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "--- "):
+			oldName = diffPath(line)
+		case strings.HasPrefix(line, "+++ "):
+			name := diffPath(line)
+			if name == "/dev/null" {
+				// The file has been deleted
+				newLines = nil
+				continue
+			}
+			if strings.HasPrefix(name, "b/") && (strings.HasPrefix(oldName, "a/") || oldName == "/dev/null") {
+				name = name[2:]
+			}
+			if !filepath.IsAbs(name) {
+				name = filepath.Join(root, filepath.FromSlash(name))
+			}
+			newLines = cs.lines[name]
+			if newLines == nil {
+				newLines = map[int]bool{}
+				cs.lines[name] = newLines
+			}
+		case strings.HasPrefix(line, "@@ "):
+			var err error
+			oldLeft, newLine, newLeft, err = parseHunkHeader(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %s", lineNumber, err)
+			}
+		default:
+			// Other header lines, such as 'diff --git' or 'index'
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return cs, nil
+}
+
+// identifiers returns all Go identifiers that occur in s. It doesn't
+// account for comments or string literals.
+func identifiers(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	})
+}
+
+// readChanges parses the unified diff in the file at path. Relative
+// file names in the diff are resolved against the current directory.
+func readChanges(path string) (*changeSet, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	return parseChanges(f, cwd)
+}
+
+// gitChanges computes the changes between the git revision rev and
+// the working tree.
+func gitChanges(rev string) (*changeSet, error) {
+	root, err := exec.Command("git", "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't determine root of git repository: %s", gitError(err))
+	}
+	out, err := exec.Command("git", "diff", "--no-color", "--no-ext-diff", "-U0", rev, "--").Output()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't compute changes relative to %s: %s", rev, gitError(err))
+	}
+	return parseChanges(bytes.NewReader(out), strings.TrimSpace(string(root)))
+}
+
+func gitError(err error) string {
+	if err, ok := err.(*exec.ExitError); ok && len(err.Stderr) > 0 {
+		return strings.TrimSpace(string(err.Stderr))
+	}
+	return err.Error()
+}
+
+// overlaps reports whether the range [pos, end] overlaps any changed
+// lines.
+func (cs *changeSet) overlaps(pos, end token.Position) bool {
+	lines := cs.lines[pos.Filename]
+	if len(lines) == 0 {
+		return false
+	}
+	last := pos.Line
+	if end.IsValid() && end.Filename == pos.Filename && end.Line > last {
+		last = end.Line
+	}
+	for l := pos.Line; l <= last; l++ {
+		if lines[l] {
+			return true
+		}
+	}
+	return false
+}
+
+// filter returns those problems that overlap changed lines. Compile
+// errors are always kept: a change can break code on lines it didn't
+// touch, for example by deleting a function that is still called.
+// Problems without positions are always kept, too.
+func (cs *changeSet) filter(ps []problem) []problem {
+	out := ps[:0]
+	for _, p := range ps {
+		if p.Category == "compile" || !p.Position.IsValid() || cs.overlaps(p.Position, p.End) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// affectsUnused reports whether the change may have caused obj to
+// become unused. This is the case if its declaration changed, or if
+// its name occurred on a deleted line, which may have been its last
+// use.
+func (cs *changeSet) affectsUnused(obj unused.SerializedObject) bool {
+	if cs.overlaps(obj.DisplayPosition, token.Position{}) {
+		return true
+	}
+	// Names of methods are of the form (*T).m or T.m
+	name := obj.Name
+	if idx := strings.LastIndexByte(name, '.'); idx >= 0 {
+		name = name[idx+1:]
+	}
+	return cs.deletedIdents[name]
+}
@@ -0,0 +1,95 @@
+package lintcmd
+
+import (
+	"fmt"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"honnef.co/go/tools/lintcmd/runner"
+	"honnef.co/go/tools/unused"
+)
+
+func TestParseChanges(t *testing.T) {
+	const in = `diff --git a/pkg/a.go b/pkg/a.go
+index b15378d..0849560 100644
+--- a/pkg/a.go
++++ b/pkg/a.go
+@@ -3,4 +3,5 @@ func g() {}
+ 
+ func h() { g() }
+ 
+-var _ = h
++
++func k() {}
+@@ -20 +21,0 @@
+-	unrelated(x)
+--- /dev/null
++++ b/new.go
+@@ -0,0 +1,2 @@
++package pkg
++var x int
+`
+	root := filepath.FromSlash("/root")
+	cs, err := parseChanges(strings.NewReader(in), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := filepath.Join(root, "pkg", "a.go")
+	pos := func(file string, line int) token.Position {
+		return token.Position{Filename: file, Line: line, Column: 1}
+	}
+	tests := []struct {
+		pos  token.Position
+		want bool
+	}{
+		{pos(a, 4), false},
+		{pos(a, 5), false},
+		{pos(a, 6), true},
+		{pos(a, 7), true},
+		{pos(a, 21), false},
+		{pos(filepath.Join(root, "new.go"), 2), true},
+		{pos(filepath.Join(root, "other.go"), 1), false},
+	}
+	for _, tt := range tests {
+		if got := cs.overlaps(tt.pos, token.Position{}); got != tt.want {
+			t.Errorf("overlaps(%s) = %t, want %t", tt.pos, got, tt.want)
+		}
+	}
+	if !cs.overlaps(pos(a, 4), pos(a, 6)) {
+		t.Errorf("range overlapping a changed line wasn't matched")
+	}
+
+	if !cs.affectsUnused(unused.SerializedObject{Name: "h", DisplayPosition: pos(a, 4)}) {
+		t.Errorf("object whose use was deleted wasn't affected")
+	}
+	if !cs.affectsUnused(unused.SerializedObject{Name: "(*T).unrelated", DisplayPosition: pos(a, 30)}) {
+		t.Errorf("method whose use was deleted wasn't affected")
+	}
+	if cs.affectsUnused(unused.SerializedObject{Name: "g", DisplayPosition: pos(a, 3)}) {
+		t.Errorf("unchanged object was affected")
+	}
+
+	prob := func(category string, line int) problem {
+		return problem{Diagnostic: runner.Diagnostic{Position: pos(a, line), Category: category}}
+	}
+	// The deleted line may have removed the declaration of something
+	// that unchanged code uses, so compile errors on unchanged lines
+	// are kept.
+	got := cs.filter([]problem{
+		prob("SA4000", 4),
+		prob("SA4000", 6),
+		prob("compile", 4),
+		{Diagnostic: runner.Diagnostic{Category: "SA4000"}},
+	})
+	var categories []string
+	for _, p := range got {
+		categories = append(categories, fmt.Sprintf("%s:%d", p.Category, p.Position.Line))
+	}
+	if want := []string{"SA4000:6", "compile:4", "SA4000:0"}; !reflect.DeepEqual(categories, want) {
+		t.Errorf("filter kept %v, want %v", categories, want)
+	}
+}
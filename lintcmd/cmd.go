@@ -8,6 +8,7 @@ import (
 	"go/token"
 	"io"
 	"log"
+	"math"
 	"os"
 	"os/signal"
 	"path/filepath"
@@ -17,14 +18,14 @@ import (
 	"sort"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 	"unicode"
 
 	"honnef.co/go/tools/analysis/lint"
 	"honnef.co/go/tools/config"
-	"honnef.co/go/tools/go/loader"
 	"honnef.co/go/tools/internal/cache"
+	"honnef.co/go/tools/internal/diff"
+	"honnef.co/go/tools/internal/renameio"
 	"honnef.co/go/tools/lintcmd/runner"
 	"honnef.co/go/tools/lintcmd/version"
 	"honnef.co/go/tools/unused"
@@ -36,30 +37,52 @@ import (
 
 type ignore interface {
 	Match(p problem) bool
+	directive() *ignoreDirective
 }
 
-type lineIgnore struct {
+// ignoreDirective holds the parts of an ignore that are shared by all
+// kinds of ignore directives.
+type ignoreDirective struct {
+	// The file containing the directive
 	File    string
-	Line    int
 	Checks  []string
 	Matched bool
-	Pos     token.Position
+	// The position of the directive
+	Pos token.Position
+	// The directive's command, such as "ignore" or "file-ignore"
+	Command string
+	Reason  string
+	// The optional expiry date, in the form YYYY-MM-DD
+	Until string
 }
 
-func (li *lineIgnore) Match(p problem) bool {
-	pos := p.Position
-	if pos.Filename != li.File || pos.Line != li.Line {
-		return false
-	}
-	for _, c := range li.Checks {
+func (id *ignoreDirective) directive() *ignoreDirective { return id }
+
+func (id *ignoreDirective) matchCheck(p problem) bool {
+	for _, c := range id.Checks {
 		if m, _ := filepath.Match(c, p.Category); m {
-			li.Matched = true
+			id.Matched = true
 			return true
 		}
 	}
 	return false
 }
 
+// A lineIgnore ignores problems on the line of the node that the
+// directive is attached to.
+type lineIgnore struct {
+	ignoreDirective
+	Line int
+}
+
+func (li *lineIgnore) Match(p problem) bool {
+	pos := p.Position
+	if pos.Filename != li.File || pos.Line != li.Line {
+		return false
+	}
+	return li.matchCheck(p)
+}
+
 func (li *lineIgnore) String() string {
 	matched := "not matched"
 	if li.Matched {
@@ -68,21 +91,44 @@ func (li *lineIgnore) String() string {
 	return fmt.Sprintf("%s:%d %s (%s)", li.File, li.Line, strings.Join(li.Checks, ", "), matched)
 }
 
+// A blockIgnore ignores problems anywhere in the node that the
+// directive is attached to.
+type blockIgnore struct {
+	ignoreDirective
+	StartLine int
+	EndLine   int
+}
+
+func (bi *blockIgnore) Match(p problem) bool {
+	pos := p.Position
+	if pos.Filename != bi.File || pos.Line < bi.StartLine || pos.Line > bi.EndLine {
+		return false
+	}
+	return bi.matchCheck(p)
+}
+
+// A fileIgnore ignores problems in the file containing the directive.
 type fileIgnore struct {
-	File   string
-	Checks []string
+	ignoreDirective
 }
 
 func (fi *fileIgnore) Match(p problem) bool {
 	if p.Position.Filename != fi.File {
 		return false
 	}
-	for _, c := range fi.Checks {
-		if m, _ := filepath.Match(c, p.Category); m {
-			return true
-		}
-	}
-	return false
+	return fi.matchCheck(p)
+}
+
+// A packageIgnore ignores problems in the package whose
+// documentation contains the directive.
+type packageIgnore struct {
+	ignoreDirective
+}
+
+func (pi *packageIgnore) Match(p problem) bool {
+	// Ignores only ever get matched against the problems of the
+	// package they belong to.
+	return pi.matchCheck(p)
 }
 
 type severity uint8
@@ -91,6 +137,9 @@ const (
 	severityError severity = iota
 	severityWarning
 	severityIgnored
+	severityBaselined
+	severityInfo
+	severityHint
 )
 
 func (s severity) String() string {
@@ -101,15 +150,39 @@ func (s severity) String() string {
 		return "warning"
 	case severityIgnored:
 		return "ignored"
+	case severityBaselined:
+		return "baselined"
+	case severityInfo:
+		return "info"
+	case severityHint:
+		return "hint"
 	default:
 		return fmt.Sprintf("Severity(%d)", s)
 	}
 }
 
+func parseSeverity(s string) (severity, bool) {
+	switch s {
+	case "error":
+		return severityError, true
+	case "warning":
+		return severityWarning, true
+	case "info":
+		return severityInfo, true
+	case "hint":
+		return severityHint, true
+	default:
+		return 0, false
+	}
+}
+
 // problem represents a problem in some source code.
 type problem struct {
 	runner.Diagnostic
 	Severity severity
+	// The import path of the package the problem was found in. This
+	// isn't set for problems that don't belong to any package.
+	Package string
 }
 
 func (p problem) equal(o problem) bool {
@@ -129,6 +202,18 @@ type linter struct {
 	Checkers []*analysis.Analyzer
 	Config   config.Config
 	Runner   *runner.Runner
+	// Optional baseline of known problems that should be suppressed
+	Baseline *baseline
+	// Optional set of changes; if set, only problems on changed lines
+	// will be reported
+	Changes *changeSet
+	// Checks that cause problems to be reported as errors instead of
+	// warnings, unless their severity has been configured. If nil,
+	// all checks do.
+	Fail []string
+	// Optional map that records how ignore directives were used,
+	// keyed by their positions
+	Directives map[token.Position]*directiveUsage
 }
 
 func failed(res runner.Result) []problem {
@@ -203,11 +288,43 @@ type unusedPair struct {
 	obj unused.SerializedObject
 }
 
-func success(allowedChecks map[string]bool, res runner.ResultData) []problem {
+// fileChecks computes the checks enabled for individual files of a
+// package, taking overrides in the package's configuration into
+// account.
+type fileChecks struct {
+	analyzers []string
+	cfg       config.Config
+	// checks enabled for files that don't match any overrides
+	pkg   map[string]bool
+	files map[string]map[string]bool
+}
+
+func newFileChecks(analyzers []string, cfg config.Config, pkg map[string]bool) *fileChecks {
+	return &fileChecks{
+		analyzers: analyzers,
+		cfg:       cfg,
+		pkg:       pkg,
+		files:     map[string]map[string]bool{},
+	}
+}
+
+func (fc *fileChecks) enabled(file, check string) bool {
+	if len(fc.cfg.Overrides) == 0 || file == "" {
+		return fc.pkg[check]
+	}
+	allowed, ok := fc.files[file]
+	if !ok {
+		allowed = filterAnalyzerNames(fc.analyzers, fc.cfg.ChecksFor(file))
+		fc.files[file] = allowed
+	}
+	return allowed[check]
+}
+
+func success(allowedChecks *fileChecks, res runner.ResultData) []problem {
 	diags := res.Diagnostics
 	var problems []problem
 	for _, diag := range diags {
-		if !allowedChecks[diag.Category] {
+		if !allowedChecks.enabled(diag.Position.Filename, diag.Category) {
 			continue
 		}
 		problems = append(problems, problem{Diagnostic: diag})
@@ -215,8 +332,12 @@ func success(allowedChecks map[string]bool, res runner.ResultData) []problem {
 	return problems
 }
 
-func filterIgnored(problems []problem, res runner.ResultData, allowedAnalyzers map[string]bool) ([]problem, error) {
-	couldveMatched := func(ig *lineIgnore) bool {
+// filterIgnored marks problems that are matched by ignore directives
+// as ignored. It records the use of directives in usage, keyed by
+// their positions, and returns problems for directives that didn't
+// match and could have.
+func filterIgnored(problems []problem, res runner.ResultData, allowedAnalyzers *fileChecks, usage map[token.Position]*directiveUsage) (filtered []problem, unmatched []problem, err error) {
+	couldveMatched := func(ig *ignoreDirective) bool {
 		for _, c := range ig.Checks {
 			if c == "U1000" {
 				// We never want to flag ignores for U1000,
@@ -234,7 +355,7 @@ func filterIgnored(problems []problem, res runner.ResultData, allowedAnalyzers m
 			// analyzers the user has expressed interest in. That way,
 			// `staticcheck -checks=SA1000` won't complain about an
 			// unmatched ignore for an unrelated check.
-			if allowedAnalyzers[c] {
+			if allowedAnalyzers.enabled(ig.File, c) {
 				return true
 			}
 		}
@@ -242,29 +363,49 @@ func filterIgnored(problems []problem, res runner.ResultData, allowedAnalyzers m
 		return false
 	}
 
-	ignores, moreProblems := parseDirectives(res.Directives)
+	var reason *regexp.Regexp
+	if pattern := allowedAnalyzers.cfg.IgnoreReasonPattern; pattern != "" {
+		var err error
+		reason, err = regexp.Compile(pattern)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	ignores, inactive, moreProblems := parseDirectives(res.Directives, reason, time.Now())
+	for _, d := range inactive {
+		if _, ok := usage[d.Directive.Pos]; !ok {
+			usage[d.Directive.Pos] = &directiveUsage{Directive: d.Directive, State: d.State}
+		}
+	}
 
 	for _, ig := range ignores {
+		id := ig.directive()
+		u, ok := usage[id.Pos]
+		if !ok {
+			u = &directiveUsage{Directive: *id}
+			usage[id.Pos] = u
+		}
 		for i := range problems {
 			p := &problems[i]
 			if ig.Match(*p) {
 				p.Severity = severityIgnored
+				u.suppress(*p)
 			}
 		}
 
-		if ig, ok := ig.(*lineIgnore); ok && !ig.Matched && couldveMatched(ig) {
+		if !id.Matched && couldveMatched(id) {
 			p := problem{
 				Diagnostic: runner.Diagnostic{
-					Position: ig.Pos,
+					Position: id.Pos,
 					Message:  "this linter directive didn't match anything; should it be removed?",
 					Category: "staticcheck",
 				},
 			}
-			moreProblems = append(moreProblems, p)
+			unmatched = append(unmatched, p)
 		}
 	}
 
-	return append(problems, moreProblems...), nil
+	return append(problems, moreProblems...), unmatched, nil
 }
 
 func newLinter(cfg config.Config) (*linter, error) {
@@ -282,10 +423,18 @@ func (l *linter) SetGoVersion(n int) {
 	l.Runner.GoVersion = n
 }
 
-func (l *linter) Lint(cfg *packages.Config, patterns []string) (problems []problem, warnings []string, err error) {
-	results, err := l.Runner.Run(cfg, l.Checkers, patterns)
-	if err != nil {
-		return nil, nil, err
+// Lint lints the packages matched by patterns once for every
+// configuration in cfgs. Problems found in more than one
+// configuration are only reported once, and objects are only reported
+// as unused if they're unused in all configurations.
+func (l *linter) Lint(cfgs []*packages.Config, patterns []string) (problems []problem, warnings []string, err error) {
+	var results []runner.Result
+	for _, cfg := range cfgs {
+		res, err := l.Runner.Run(cfg, l.Checkers, patterns)
+		if err != nil {
+			return nil, nil, err
+		}
+		results = append(results, res...)
 	}
 
 	if len(results) == 0 && err == nil {
@@ -293,7 +442,12 @@ func (l *linter) Lint(cfg *packages.Config, patterns []string) (problems []probl
 		// access to go list's Match field.
 		fmt.Fprintf(os.Stderr, "warning: %q matched no packages\n", patterns)
 	}
+	return l.problems(results)
+}
 
+// problems computes the problems to report for the results of
+// analyzing packages.
+func (l *linter) problems(results []runner.Result) (problems []problem, warnings []string, err error) {
 	analyzerNames := make([]string, len(l.Checkers))
 	for i, a := range l.Checkers {
 		analyzerNames[i] = a.Name
@@ -301,12 +455,40 @@ func (l *linter) Lint(cfg *packages.Config, patterns []string) (problems []probl
 
 	used := map[unusedKey]bool{}
 	var unuseds []unusedPair
+	// the set of checks enabled for each initial package
+	checked := map[string]map[string]bool{}
+	// the configured severities of checks for each initial package
+	severities := map[string]map[string]severity{}
+	// the configuration files used by initial packages
+	configFiles := map[string]bool{}
+	// the use of ignore directives, keyed by their positions. A file
+	// may be part of several packages and configurations, and a
+	// directive is only unmatched if it is unmatched in all of them.
+	usage := l.Directives
+	if usage == nil {
+		usage = map[token.Position]*directiveUsage{}
+	}
+	var unmatchedIgnores []problem
 	for _, res := range results {
 		if len(res.Errors) > 0 && !res.Failed {
 			panic("package has errors but isn't marked as failed")
 		}
+		if res.Initial {
+			if dir := config.Dir(res.Package.GoFiles); dir != "" {
+				files, err := config.Files(dir)
+				if err != nil {
+					return nil, nil, err
+				}
+				for _, f := range files {
+					configFiles[f] = true
+				}
+			}
+		}
 		if res.Failed {
-			problems = append(problems, failed(res)...)
+			for _, p := range failed(res) {
+				p.Package = res.Package.PkgPath
+				problems = append(problems, p)
+			}
 		} else {
 			if res.Skipped {
 				warnings = append(warnings, fmt.Sprintf("skipped package %s because it is too large", res.Package))
@@ -318,16 +500,26 @@ func (l *linter) Lint(cfg *packages.Config, patterns []string) (problems []probl
 			}
 
 			allowedAnalyzers := filterAnalyzerNames(analyzerNames, res.Config.Checks)
+			checked[res.Package.PkgPath] = allowedAnalyzers
+			severities[res.Package.PkgPath] = severitiesFor(analyzerNames, res.Config.Severity)
 			resd, err := res.Load()
 			if err != nil {
 				return nil, nil, err
 			}
-			ps := success(allowedAnalyzers, resd)
-			filtered, err := filterIgnored(ps, resd, allowedAnalyzers)
+			fc := newFileChecks(analyzerNames, res.Config, allowedAnalyzers)
+			ps := success(fc, resd)
+			filtered, unmatched, err := filterIgnored(ps, resd, fc, usage)
 			if err != nil {
 				return nil, nil, err
 			}
-			problems = append(problems, filtered...)
+			for _, p := range filtered {
+				p.Package = res.Package.PkgPath
+				problems = append(problems, p)
+			}
+			for _, p := range unmatched {
+				p.Package = res.Package.PkgPath
+				unmatchedIgnores = append(unmatchedIgnores, p)
+			}
 
 			for _, obj := range resd.Unused.Used {
 				// FIXME(dh): pick the object whose filename does not include $GOROOT
@@ -340,23 +532,36 @@ func (l *linter) Lint(cfg *packages.Config, patterns []string) (problems []probl
 				used[key] = true
 			}
 
-			if allowedAnalyzers["U1000"] {
-				for _, obj := range resd.Unused.Unused {
-					key := unusedKey{
-						pkgPath: res.Package.PkgPath,
-						base:    filepath.Base(obj.Position.Filename),
-						line:    obj.Position.Line,
-						name:    obj.Name,
-					}
-					unuseds = append(unuseds, unusedPair{key, obj})
-					if _, ok := used[key]; !ok {
-						used[key] = false
-					}
+			for _, obj := range resd.Unused.Unused {
+				if !fc.enabled(obj.DisplayPosition.Filename, "U1000") {
+					continue
+				}
+				key := unusedKey{
+					pkgPath: res.Package.PkgPath,
+					base:    filepath.Base(obj.Position.Filename),
+					line:    obj.Position.Line,
+					name:    obj.Name,
+				}
+				unuseds = append(unuseds, unusedPair{key, obj})
+				if _, ok := used[key]; !ok {
+					used[key] = false
 				}
 			}
 		}
 	}
 
+	for _, p := range unmatchedIgnores {
+		if !usage[p.Position].Matched {
+			problems = append(problems, p)
+		}
+	}
+
+	problems = append(problems, validateConfigs(configFiles, analyzerNames)...)
+
+	if l.Changes != nil {
+		problems = l.Changes.filter(problems)
+	}
+
 	for _, uo := range unuseds {
 		if used[uo.key] {
 			continue
@@ -364,19 +569,42 @@ func (l *linter) Lint(cfg *packages.Config, patterns []string) (problems []probl
 		if uo.obj.InGenerated {
 			continue
 		}
+		if l.Changes != nil && !l.Changes.affectsUnused(uo.obj) {
+			continue
+		}
 		problems = append(problems, problem{
 			Diagnostic: runner.Diagnostic{
 				Position: uo.obj.DisplayPosition,
 				Message:  fmt.Sprintf("%s %s is unused", uo.obj.Kind, uo.obj.Name),
 				Category: "U1000",
 			},
+			Package: uo.key.pkgPath,
 		})
 	}
 
-	if len(problems) == 0 {
+	if len(problems) == 0 && l.Baseline == nil {
 		return nil, warnings, nil
 	}
 
+	fail := l.Fail
+	if fail == nil {
+		fail = []string{"all"}
+	}
+	shouldFail := filterAnalyzerNames(analyzerNames, fail)
+	shouldFail["staticcheck"] = true
+	shouldFail["config"] = true
+	for i := range problems {
+		p := &problems[i]
+		if p.Category == "compile" || p.Severity != severityError {
+			continue
+		}
+		if sev, ok := severities[p.Package][p.Category]; ok {
+			p.Severity = sev
+		} else if !shouldFail[p.Category] {
+			p.Severity = severityWarning
+		}
+	}
+
 	sort.Slice(problems, func(i, j int) bool {
 		pi := problems[i].Position
 		pj := problems[j].Position
@@ -395,14 +623,20 @@ func (l *linter) Lint(cfg *packages.Config, patterns []string) (problems []probl
 	})
 
 	var out []problem
-	out = append(out, problems[0])
-	for i, p := range problems[1:] {
-		// We may encounter duplicate problems because one file
-		// can be part of many packages.
-		if !problems[i].equal(p) {
-			out = append(out, p)
+	if len(problems) > 0 {
+		out = append(out, problems[0])
+		for i, p := range problems[1:] {
+			// We may encounter duplicate problems because one file
+			// can be part of many packages.
+			if !problems[i].equal(p) {
+				out = append(out, p)
+			}
 		}
 	}
+
+	if l.Baseline != nil {
+		out = l.Baseline.apply(out, checked)
+	}
 	return out, warnings, nil
 }
 
@@ -448,6 +682,82 @@ func filterAnalyzerNames(analyzers []string, checks []string) map[string]bool {
 	return allowedChecks
 }
 
+// validateConfigs reports problems in configuration files. Files
+// that can't be parsed at all have already caused errors when loading
+// packages.
+func validateConfigs(files map[string]bool, analyzers []string) []problem {
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var problems []problem
+	for _, path := range paths {
+		cps, err := config.Validate(path, analyzers)
+		if err != nil {
+			continue
+		}
+		for _, cp := range cps {
+			problems = append(problems, problem{
+				Diagnostic: runner.Diagnostic{
+					Position: cp.Position,
+					Message:  cp.Message,
+					Category: "config",
+				},
+			})
+		}
+	}
+	return problems
+}
+
+// severitiesFor resolves the configured severities of analyzers.
+// Entries in sevs use the same syntax as the checks option. When
+// several entries match an analyzer, the most specific one wins:
+// literal check names take precedence over globs, and longer globs
+// take precedence over shorter ones.
+func severitiesFor(analyzers []string, sevs map[string]string) map[string]severity {
+	if len(sevs) == 0 {
+		return nil
+	}
+	specificity := func(pattern string) int {
+		switch {
+		case pattern == "*" || pattern == "all":
+			return 0
+		case strings.HasSuffix(pattern, "*"):
+			return len(pattern)
+		default:
+			return math.MaxInt32
+		}
+	}
+	patterns := make([]string, 0, len(sevs))
+	for pattern := range sevs {
+		patterns = append(patterns, pattern)
+	}
+	sort.Slice(patterns, func(i, j int) bool {
+		si, sj := specificity(patterns[i]), specificity(patterns[j])
+		if si != sj {
+			return si < sj
+		}
+		return patterns[i] < patterns[j]
+	})
+
+	out := map[string]severity{}
+	for _, pattern := range patterns {
+		sev, ok := parseSeverity(sevs[pattern])
+		if !ok {
+			// config.Load has already rejected invalid severities
+			continue
+		}
+		for c, b := range filterAnalyzerNames(analyzers, []string{pattern}) {
+			if b {
+				out[c] = sev
+			}
+		}
+	}
+	return out
+}
+
 var posRe = regexp.MustCompile(`^(.+?):(\d+)(?::(\d+)?)?`)
 
 func parsePos(pos string) (token.Position, int, error) {
@@ -496,21 +806,100 @@ func (list *list) Set(s string) error {
 	return nil
 }
 
+// byteSize is a size in bytes, which can be set with a unit, as in
+// "512MiB" or "2GB".
+type byteSize uint64
+
+var byteUnits = []struct {
+	suffix string
+	size   uint64
+}{
+	// longer suffixes come first so that "KiB" isn't parsed as "B"
+	{"KiB", 1 << 10},
+	{"MiB", 1 << 20},
+	{"GiB", 1 << 30},
+	{"TiB", 1 << 40},
+	{"KB", 1e3},
+	{"MB", 1e6},
+	{"GB", 1e9},
+	{"TB", 1e12},
+	{"K", 1 << 10},
+	{"M", 1 << 20},
+	{"G", 1 << 30},
+	{"T", 1 << 40},
+	{"B", 1},
+}
+
+func (b *byteSize) String() string {
+	return formatBytes(uint64(*b))
+}
+
+func (b *byteSize) Set(s string) error {
+	n, mul := s, uint64(1)
+	for _, u := range byteUnits {
+		if strings.HasSuffix(s, u.suffix) {
+			n, mul = strings.TrimSpace(strings.TrimSuffix(s, u.suffix)), u.size
+			break
+		}
+	}
+	v, err := strconv.ParseFloat(n, 64)
+	if err != nil || v < 0 {
+		return fmt.Errorf("invalid size %q", s)
+	}
+	*b = byteSize(v * float64(mul))
+	return nil
+}
+
+func (b *byteSize) Get() interface{} { return uint64(*b) }
+
+// formatBytes formats n using the largest binary unit that keeps the
+// number at least 1.
+func formatBytes(n uint64) string {
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB"}
+	f := float64(n)
+	i := 0
+	for f >= 1024 && i < len(units)-1 {
+		f /= 1024
+		i++
+	}
+	if i == 0 {
+		return fmt.Sprintf("%dB", n)
+	}
+	return fmt.Sprintf("%.1f%s", f, units[i])
+}
+
 func FlagSet(name string) *flag.FlagSet {
 	flags := flag.NewFlagSet("", flag.ExitOnError)
 	flags.Usage = usage(name, flags)
 	flags.String("tags", "", "List of `build tags`")
+	flags.String("matrix", "", "Comma-separated list of `configurations` of the form GOOS/GOARCH[;tags=TAGS] to check and merge the results of")
 	flags.Bool("tests", true, "Include tests")
+	flags.Int("j", 0, "Maximum `number` of packages and analyzers to process in parallel (default: number of CPUs)")
+	flags.Var(new(byteSize), "max-memory", "Stop scheduling new packages while heap usage exceeds `size`, such as 4GiB (default: no limit)")
+	flags.String("cache", "", "Run a cache maintenance `command` (valid choices are 'stats', 'trim', 'clean' and 'verify') and exit")
+	flags.Var(new(byteSize), "max-size", "With -cache trim, remove the least recently used files until the cache is no larger than `size`")
+	flags.Duration("max-age", 0, "With -cache trim, remove files that haven't been used in `duration`, such as 720h")
 	flags.Bool("version", false, "Print version and exit")
 	flags.Bool("show-ignored", false, "Don't filter ignored problems")
-	flags.String("f", "text", "Output `format` (valid choices are 'stylish', 'text' and 'json')")
-	flags.String("explain", "", "Print description of `check`")
+	flags.String("f", "text", "Output `format` (valid choices are 'stylish', 'text', 'json', 'sarif', 'checkstyle', 'junit', 'codeclimate' and 'github')")
+	flags.String("explain", "", "Print description of `checks`")
+	flags.Bool("list-checks", false, "List all checks and whether the configuration in the current directory enables them")
+	flags.Bool("list-ignores", false, "List all ignore directives in the packages, whether they are active, expired or malformed, and how many problems they suppress. This runs all checks, because the problems have to be found to count them")
+	flags.Bool("lsp", false, "Run as a language server, communicating over stdin and stdout")
+	flags.Bool("watch", false, "Keep running and report new and fixed problems whenever files change")
+	flags.Bool("fix", false, "Apply suggested fixes to source files")
+	flags.Bool("diff", false, "Print suggested fixes as a unified diff instead of applying them")
+	flags.String("baseline", "", "Don't report problems recorded in the baseline `file`")
+	flags.String("baseline-write", "", "Record all current problems in the baseline `file`")
+	flags.String("diff-base", "", "Only report problems on lines changed relative to the git `revision`")
+	flags.String("changed-lines", "", "Only report problems on lines changed by the unified diff in `file`")
 
 	flags.String("debug.cpuprofile", "", "Write CPU profile to `file`")
 	flags.String("debug.memprofile", "", "Write memory profile to `file`")
 	flags.Bool("debug.version", false, "Print detailed version information about this program")
 	flags.Bool("debug.no-compile-errors", false, "Don't print compile errors")
-	flags.String("debug.measure-analyzers", "", "Write analysis measurements to `file`. `file` will be opened for appending if it already exists.")
+	flags.String("debug.config", "", "Print the effective configuration for packages in `directory` and exit")
+	flags.String("debug.profile-report", "", "Write a report of where time and memory were spent to `file`, as JSON if the name ends in .json and as tables otherwise")
 
 	checks := list{"inherit"}
 	fail := list{"all"}
@@ -539,34 +928,37 @@ func findCheck(cs []*analysis.Analyzer, check string) (*analysis.Analyzer, bool)
 
 func ProcessFlagSet(cs []*analysis.Analyzer, fs *flag.FlagSet) {
 	tags := fs.Lookup("tags").Value.(flag.Getter).Get().(string)
+	matrixFlag := fs.Lookup("matrix").Value.(flag.Getter).Get().(string)
 	tests := fs.Lookup("tests").Value.(flag.Getter).Get().(bool)
+	workers := fs.Lookup("j").Value.(flag.Getter).Get().(int)
+	maxMemory := fs.Lookup("max-memory").Value.(flag.Getter).Get().(uint64)
+	cacheCmd := fs.Lookup("cache").Value.(flag.Getter).Get().(string)
 	goVersion := fs.Lookup("go").Value.(flag.Getter).Get().(int)
 	theFormatter := fs.Lookup("f").Value.(flag.Getter).Get().(string)
 	printVersion := fs.Lookup("version").Value.(flag.Getter).Get().(bool)
 	showIgnored := fs.Lookup("show-ignored").Value.(flag.Getter).Get().(bool)
 	explain := fs.Lookup("explain").Value.(flag.Getter).Get().(string)
+	applyFixes := fs.Lookup("fix").Value.(flag.Getter).Get().(bool)
+	printDiff := fs.Lookup("diff").Value.(flag.Getter).Get().(bool)
+	baselineFile := fs.Lookup("baseline").Value.(flag.Getter).Get().(string)
+	baselineWriteFile := fs.Lookup("baseline-write").Value.(flag.Getter).Get().(string)
+	diffBase := fs.Lookup("diff-base").Value.(flag.Getter).Get().(string)
+	changedLinesFile := fs.Lookup("changed-lines").Value.(flag.Getter).Get().(string)
+	lsp := fs.Lookup("lsp").Value.(flag.Getter).Get().(bool)
+	watchMode := fs.Lookup("watch").Value.(flag.Getter).Get().(bool)
+	printChecks := fs.Lookup("list-checks").Value.(flag.Getter).Get().(bool)
+	printIgnores := fs.Lookup("list-ignores").Value.(flag.Getter).Get().(bool)
 
 	cpuProfile := fs.Lookup("debug.cpuprofile").Value.(flag.Getter).Get().(string)
 	memProfile := fs.Lookup("debug.memprofile").Value.(flag.Getter).Get().(string)
 	debugVersion := fs.Lookup("debug.version").Value.(flag.Getter).Get().(bool)
 	debugNoCompile := fs.Lookup("debug.no-compile-errors").Value.(flag.Getter).Get().(bool)
+	debugConfig := fs.Lookup("debug.config").Value.(flag.Getter).Get().(string)
 
-	var measureAnalyzers func(analysis *analysis.Analyzer, pkg *loader.PackageSpec, d time.Duration)
-	if path := fs.Lookup("debug.measure-analyzers").Value.(flag.Getter).Get().(string); path != "" {
-		f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
-		if err != nil {
-			log.Fatal(err)
-		}
-
-		mu := &sync.Mutex{}
-		measureAnalyzers = func(analysis *analysis.Analyzer, pkg *loader.PackageSpec, d time.Duration) {
-			mu.Lock()
-			defer mu.Unlock()
-			// FIXME(dh): print pkg.ID
-			if _, err := fmt.Fprintf(f, "%s\t%s\t%d\n", analysis.Name, pkg, d.Nanoseconds()); err != nil {
-				log.Println("error writing analysis measurements:", err)
-			}
-		}
+	profileReport := fs.Lookup("debug.profile-report").Value.(flag.Getter).Get().(string)
+	var profile *runner.Profile
+	if profileReport != "" {
+		profile = &runner.Profile{}
 	}
 
 	cfg := config.Config{}
@@ -604,6 +996,37 @@ func ProcessFlagSet(cs []*analysis.Analyzer, fs *flag.FlagSet) {
 		exit(0)
 	}
 
+	if debugConfig != "" {
+		dir, err := filepath.Abs(debugConfig)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			exit(1)
+		}
+		if err := config.Describe(os.Stdout, dir); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			exit(1)
+		}
+		exit(0)
+	}
+
+	if cacheCmd != "" {
+		c, err := cache.Default()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			exit(1)
+		}
+		err = runCacheCommand(os.Stdout, c, cacheCmd, cacheOptions{
+			MaxSize: fs.Lookup("max-size").Value.(flag.Getter).Get().(uint64),
+			MaxAge:  fs.Lookup("max-age").Value.(flag.Getter).Get().(time.Duration),
+			Format:  theFormatter,
+		})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			exit(1)
+		}
+		exit(0)
+	}
+
 	// Validate that the tags argument is well-formed. go/packages
 	// doesn't detect malformed build flags and returns unhelpful
 	// errors.
@@ -613,19 +1036,116 @@ func ProcessFlagSet(cs []*analysis.Analyzer, fs *flag.FlagSet) {
 		exit(1)
 	}
 
+	if workers < 0 {
+		fmt.Fprintln(os.Stderr, "invalid value for flag -j: must not be negative")
+		exit(1)
+	}
+
+	var matrix []buildConfig
+	if matrixFlag != "" {
+		var err error
+		matrix, err = parseMatrix(matrixFlag)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, fmt.Errorf("invalid value %q for flag -matrix: %s", matrixFlag, err))
+			exit(1)
+		}
+	}
+
 	if explain != "" {
-		var haystack []*analysis.Analyzer
-		haystack = append(haystack, cs...)
-		check, ok := findCheck(haystack, explain)
-		if !ok {
+		checks := findChecks(cs, explain)
+		if len(checks) == 0 {
 			fmt.Fprintln(os.Stderr, "Couldn't find check", explain)
 			exit(1)
 		}
-		if check.Doc == "" {
+		if len(checks) == 1 && checks[0].Doc == "" {
 			fmt.Fprintln(os.Stderr, explain, "has no documentation")
 			exit(1)
 		}
-		fmt.Println(check.Doc)
+		if err := explainChecks(os.Stdout, checks, theFormatter); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			exit(2)
+		}
+		exit(0)
+	}
+
+	if printChecks {
+		cwd, err := os.Getwd()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			exit(1)
+		}
+		ccfg, err := config.Load(cwd)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			exit(1)
+		}
+		names := make([]string, len(cs))
+		for i, c := range cs {
+			names[i] = c.Name
+		}
+		// Checks passed via -checks apply on top of configuration
+		// files, just like when linting.
+		enabled := filterAnalyzerNames(names, ccfg.Merge(cfg).Checks)
+		if err := listChecks(os.Stdout, describeChecks(cs, enabled), theFormatter); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			exit(2)
+		}
+		exit(0)
+	}
+
+	if lsp {
+		opt := &options{
+			Tags:      tags,
+			Matrix:    matrix,
+			Workers:   workers,
+			MaxMemory: maxMemory,
+			LintTests: tests,
+			GoVersion: goVersion,
+			Config:    cfg,
+			Fail:      *fs.Lookup("fail").Value.(*list),
+		}
+		l, pcfgs, err := setupLinter(cs, opt)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			exit(1)
+		}
+		il := &incrementalLinter{l: l, cfgs: pcfgs}
+		s := newLSPServer(cs, il.lint, os.Stdout, os.Stderr)
+		ok, err := s.serve(os.Stdin)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			exit(1)
+		}
+		if !ok {
+			// The client exited without shutting us down first
+			exit(1)
+		}
+		exit(0)
+	}
+
+	if printIgnores {
+		usage := map[token.Position]*directiveUsage{}
+		_, warnings, err := doLint(cs, fs.Args(), &options{
+			Tags:       tags,
+			Matrix:     matrix,
+			Workers:    workers,
+			MaxMemory:  maxMemory,
+			LintTests:  tests,
+			GoVersion:  goVersion,
+			Config:     cfg,
+			Directives: usage,
+		})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			exit(1)
+		}
+		for _, w := range warnings {
+			fmt.Fprintln(os.Stderr, "warning:", w)
+		}
+		if err := listIgnores(os.Stdout, usage, theFormatter); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			exit(2)
+		}
 		exit(0)
 	}
 
@@ -637,41 +1157,187 @@ func ProcessFlagSet(cs []*analysis.Analyzer, fs *flag.FlagSet) {
 		f = &stylishFormatter{W: os.Stdout}
 	case "json":
 		f = jsonFormatter{W: os.Stdout}
+	case "sarif":
+		f = &sarifFormatter{W: os.Stdout, Checks: cs}
+	case "checkstyle":
+		f = &checkstyleFormatter{W: os.Stdout}
+	case "junit":
+		f = &junitFormatter{W: os.Stdout}
+	case "codeclimate":
+		f = &codeClimateFormatter{W: os.Stdout}
+	case "github":
+		f = githubFormatter{W: os.Stdout}
 	default:
 		fmt.Fprintf(os.Stderr, "unsupported output format %q\n", theFormatter)
 		exit(2)
 	}
 
+	var bl *baseline
+	if baselineFile != "" {
+		var err error
+		bl, err = readBaseline(baselineFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "couldn't read baseline:", err)
+			exit(1)
+		}
+	}
+
+	var changes *changeSet
+	if diffBase != "" && changedLinesFile != "" {
+		fmt.Fprintln(os.Stderr, "-diff-base and -changed-lines are mutually exclusive")
+		exit(2)
+	} else if diffBase != "" {
+		var err error
+		changes, err = gitChanges(diffBase)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			exit(1)
+		}
+	} else if changedLinesFile != "" {
+		var err error
+		changes, err = readChanges(changedLinesFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "couldn't read changed lines:", err)
+			exit(1)
+		}
+	}
+
+	if watchMode {
+		if theFormatter != "text" {
+			fmt.Fprintf(os.Stderr, "unsupported output format %q for -watch\n", theFormatter)
+			exit(2)
+		}
+		if applyFixes || printDiff || baselineWriteFile != "" || changes != nil || profileReport != "" {
+			fmt.Fprintln(os.Stderr, "-watch can't be combined with -fix, -diff, -baseline-write, -diff-base, -changed-lines or -debug.profile-report")
+			exit(2)
+		}
+		l, pcfgs, err := setupLinter(cs, &options{
+			Tags:      tags,
+			Matrix:    matrix,
+			Workers:   workers,
+			MaxMemory: maxMemory,
+			LintTests: tests,
+			GoVersion: goVersion,
+			Config:    cfg,
+			Baseline:  bl,
+			Fail:      *fs.Lookup("fail").Value.(*list),
+		})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			exit(1)
+		}
+		err = watch(l, pcfgs, fs.Args(), os.Stdout, func(p problem) bool {
+			if p.Category == "compile" && debugNoCompile {
+				return false
+			}
+			return showIgnored || (p.Severity != severityIgnored && p.Severity != severityBaselined)
+		})
+		fmt.Fprintln(os.Stderr, err)
+		exit(1)
+	}
+
 	ps, warnings, err := doLint(cs, fs.Args(), &options{
-		Tags:                     tags,
-		LintTests:                tests,
-		GoVersion:                goVersion,
-		Config:                   cfg,
-		PrintAnalyzerMeasurement: measureAnalyzers,
+		Tags:      tags,
+		Matrix:    matrix,
+		Workers:   workers,
+		MaxMemory: maxMemory,
+		LintTests: tests,
+		GoVersion: goVersion,
+		Config:    cfg,
+		Baseline:  bl,
+		Changes:   changes,
+		Fail:      *fs.Lookup("fail").Value.(*list),
+		Profile:   profile,
 	})
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		exit(1)
 	}
 
+	if profileReport != "" {
+		format := "text"
+		if filepath.Ext(profileReport) == ".json" {
+			format = "json"
+		}
+		f, err := os.Create(profileReport)
+		if err == nil {
+			err = writeProfile(f, profile, format)
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "couldn't write profile report:", err)
+			exit(1)
+		}
+	}
+
+	if baselineWriteFile != "" {
+		n, err := writeBaseline(baselineWriteFile, ps)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "couldn't write baseline:", err)
+			exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "recorded %d problems in %s\n", n, baselineWriteFile)
+		// Everything we've just recorded is now part of the baseline.
+		for i := range ps {
+			if baselinable(ps[i]) {
+				ps[i].Severity = severityBaselined
+			}
+		}
+	}
+
 	for _, w := range warnings {
 		fmt.Fprintln(os.Stderr, "warning:", w)
 	}
 
-	var (
-		numCompiles int
-		numErrors   int
-		numWarnings int
-		numIgnored  int
-	)
+	if applyFixes || printDiff {
+		files, fixed, warnings, err := computeFixes(ps)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			exit(1)
+		}
+		for _, w := range warnings {
+			fmt.Fprintln(os.Stderr, "warning:", w)
+		}
 
-	fail := *fs.Lookup("fail").Value.(*list)
-	analyzerNames := make([]string, len(cs))
-	for i, a := range cs {
-		analyzerNames[i] = a.Name
+		if printDiff {
+			for _, f := range files {
+				name := filepath.ToSlash(shortPath(f.Name))
+				fmt.Print(diff.Unified("a/"+name, "b/"+name, f.Old, f.New))
+			}
+			exit(0)
+		}
+
+		for _, f := range files {
+			fi, err := os.Stat(f.Name)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				exit(1)
+			}
+			if err := renameio.WriteFile(f.Name, f.New, fi.Mode()); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				exit(1)
+			}
+		}
+
+		// Problems that we've fixed don't need to be reported.
+		var remaining []problem
+		for i, p := range ps {
+			if !fixed[i] {
+				remaining = append(remaining, p)
+			}
+		}
+		ps = remaining
 	}
-	shouldExit := filterAnalyzerNames(analyzerNames, fail)
-	shouldExit["staticcheck"] = true
+
+	var (
+		numCompiles  int
+		numErrors    int
+		numWarnings  int
+		numIgnored   int
+		numBaselined int
+	)
 
 	for _, p := range ps {
 		if p.Category == "compile" && debugNoCompile {
@@ -681,18 +1347,31 @@ func ProcessFlagSet(cs []*analysis.Analyzer, fs *flag.FlagSet) {
 			numIgnored++
 			continue
 		}
-		if p.Category == "compile" {
+		if p.Severity == severityBaselined {
+			numBaselined++
+			if !showIgnored {
+				continue
+			}
+			f.Format(p)
+			continue
+		}
+		switch {
+		case p.Category == "compile":
 			numCompiles++
-		} else if shouldExit[p.Category] {
+		case p.Severity == severityError:
 			numErrors++
-		} else {
-			p.Severity = severityWarning
+		case p.Severity == severityIgnored:
+			numIgnored++
+		default:
 			numWarnings++
 		}
 		f.Format(p)
 	}
+	if f, ok := f.(documentFormatter); ok {
+		f.Finish()
+	}
 	if f, ok := f.(statter); ok {
-		f.Stats(len(ps), numErrors+numCompiles, numWarnings, numIgnored)
+		f.Stats(len(ps), numErrors+numCompiles, numWarnings, numIgnored, numBaselined)
 	}
 
 	if f, ok := f.(documentationMentioner); ok && (numErrors > 0 || numWarnings > 0) && len(os.Args) > 0 {
@@ -708,10 +1387,17 @@ func ProcessFlagSet(cs []*analysis.Analyzer, fs *flag.FlagSet) {
 type options struct {
 	Config config.Config
 
-	Tags                     string
-	LintTests                bool
-	GoVersion                int
-	PrintAnalyzerMeasurement func(analysis *analysis.Analyzer, pkg *loader.PackageSpec, d time.Duration)
+	Tags       string
+	Matrix     []buildConfig
+	LintTests  bool
+	GoVersion  int
+	Baseline   *baseline
+	Changes    *changeSet
+	Fail       []string
+	Workers    int
+	MaxMemory  uint64
+	Directives map[token.Position]*directiveUsage
+	Profile    *runner.Profile
 }
 
 func computeSalt() ([]byte, error) {
@@ -734,7 +1420,10 @@ func computeSalt() ([]byte, error) {
 	return h.Sum(nil), nil
 }
 
-func doLint(cs []*analysis.Analyzer, paths []string, opt *options) ([]problem, []string, error) {
+// setupLinter creates a linter for the checks in cs, configured by
+// opt, as well as the configurations for loading packages, one per
+// entry in opt.Matrix.
+func setupLinter(cs []*analysis.Analyzer, opt *options) (*linter, []*packages.Config, error) {
 	salt, err := computeSalt()
 	if err != nil {
 		return nil, nil, fmt.Errorf("could not compute salt for cache: %s", err)
@@ -750,15 +1439,49 @@ func doLint(cs []*analysis.Analyzer, paths []string, opt *options) ([]problem, [
 		return nil, nil, err
 	}
 	l.Checkers = cs
+	l.Baseline = opt.Baseline
+	l.Changes = opt.Changes
+	l.Fail = opt.Fail
+	l.Directives = opt.Directives
 	l.SetGoVersion(opt.GoVersion)
-	l.Runner.Stats.PrintAnalyzerMeasurement = opt.PrintAnalyzerMeasurement
+	if opt.Workers > 0 {
+		l.Runner.SetWorkers(opt.Workers)
+	}
+	l.Runner.MaxMemory = opt.MaxMemory
+	l.Runner.Profile = opt.Profile
 
-	cfg := &packages.Config{}
-	if opt.LintTests {
-		cfg.Tests = true
+	matrix := opt.Matrix
+	if len(matrix) == 0 {
+		matrix = []buildConfig{{}}
+	}
+	cfgs := make([]*packages.Config, len(matrix))
+	for i, bc := range matrix {
+		cfg := &packages.Config{}
+		if opt.LintTests {
+			cfg.Tests = true
+		}
+		tags := opt.Tags
+		if bc.Tags != "" {
+			if tags != "" {
+				tags += " "
+			}
+			tags += bc.Tags
+		}
+		if tags != "" {
+			cfg.BuildFlags = append(cfg.BuildFlags, "-tags", tags)
+		}
+		if bc.GOOS != "" {
+			cfg.Env = append(os.Environ(), "GOOS="+bc.GOOS, "GOARCH="+bc.GOARCH)
+		}
+		cfgs[i] = cfg
 	}
-	if opt.Tags != "" {
-		cfg.BuildFlags = append(cfg.BuildFlags, "-tags", opt.Tags)
+	return l, cfgs, nil
+}
+
+func doLint(cs []*analysis.Analyzer, paths []string, opt *options) ([]problem, []string, error) {
+	l, cfgs, err := setupLinter(cs, opt)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	printStats := func() {
@@ -773,7 +1496,7 @@ func doLint(cs []*analysis.Analyzer, paths []string, opt *options) ([]problem, [
 		case runner.StateBuildActionGraph:
 			fmt.Fprintln(os.Stderr, "Status: building action graph")
 		case runner.StateProcessing:
-			fmt.Fprintf(os.Stderr, "Packages: %d/%d initial, %d/%d total; Workers: %d/%d\n",
+			fmt.Fprintf(os.Stderr, "Packages: %d/%d initial, %d/%d total; Workers: %d/%d",
 				l.Runner.Stats.ProcessedInitialPackages(),
 				l.Runner.Stats.InitialPackages(),
 				l.Runner.Stats.ProcessedPackages(),
@@ -781,6 +1504,17 @@ func doLint(cs []*analysis.Analyzer, paths []string, opt *options) ([]problem, [
 				l.Runner.ActiveWorkers(),
 				l.Runner.TotalWorkers(),
 			)
+			if l.Runner.MaxMemory != 0 {
+				fmt.Fprintf(os.Stderr, "; Memory: %s/%s",
+					formatBytes(l.Runner.Stats.HeapAlloc()),
+					formatBytes(l.Runner.MaxMemory))
+				if l.Runner.Stats.Throttled() {
+					fmt.Fprint(os.Stderr, ", waiting for memory")
+				}
+				fmt.Fprintf(os.Stderr, " (paused %d times)", l.Runner.Stats.Throttles())
+			}
+			fmt.Fprintf(os.Stderr, "; In-memory cache: %.0f%% hits", 100*l.Runner.Stats.MemoryCacheHitRate())
+			fmt.Fprintln(os.Stderr)
 		case runner.StateFinalizing:
 			fmt.Fprintln(os.Stderr, "Status: finalizing")
 		}
@@ -795,5 +1529,11 @@ func doLint(cs []*analysis.Analyzer, paths []string, opt *options) ([]problem, [
 			}
 		}()
 	}
-	return l.Lint(cfg, paths)
+	ps, warnings, err := l.Lint(cfgs, paths)
+	if c, cerr := cache.Default(); cerr == nil {
+		if berr := c.BackendError(); berr != nil {
+			warnings = append(warnings, fmt.Sprintf("couldn't use remote cache: %s", berr))
+		}
+	}
+	return ps, warnings, err
 }
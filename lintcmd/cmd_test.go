@@ -1,8 +1,16 @@
 package lintcmd
 
 import (
+	"bytes"
+	"encoding/json"
 	"go/token"
+	"reflect"
 	"testing"
+
+	"honnef.co/go/tools/analysis/lint"
+	"honnef.co/go/tools/config"
+
+	"golang.org/x/tools/go/analysis"
 )
 
 func TestParsePos(t *testing.T) {
@@ -43,3 +51,159 @@ func TestParsePos(t *testing.T) {
 		}
 	}
 }
+
+func TestSeveritiesFor(t *testing.T) {
+	analyzers := []string{"S1000", "SA1000", "SA1001", "SA4006", "ST1000", "ST1003"}
+	parent := config.Config{Severity: map[string]string{
+		"all":    "warning",
+		"ST1000": "error",
+		"SA1*":   "info",
+	}}
+	child := config.Config{Severity: map[string]string{
+		"ST*":    "hint",
+		"SA1001": "error",
+	}}
+
+	got := severitiesFor(analyzers, config.DefaultConfig.Merge(parent).Merge(child).Severity)
+	want := map[string]severity{
+		"S1000":  severityWarning,
+		"SA1000": severityInfo,
+		"SA1001": severityError,
+		"SA4006": severityWarning,
+		// ST* in the child overrides ST1000 in the parent
+		"ST1000": severityHint,
+		"ST1003": severityHint,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	if got := severitiesFor(analyzers, config.DefaultConfig.Severity); got != nil {
+		t.Errorf("got %v for the default configuration, want nil", got)
+	}
+}
+
+func TestListChecks(t *testing.T) {
+	cs := []*analysis.Analyzer{
+		{Name: "SA9999", Doc: "Second check\n\nMore text"},
+		{Name: "S9999", Doc: "First check"},
+	}
+	infos := describeChecks(cs, map[string]bool{"SA9999": true})
+	want := []checkInfo{
+		{Name: "S9999", Category: "S", Title: "First check", Default: true},
+		{Name: "SA9999", Category: "SA", Title: "Second check", Default: true, Enabled: true},
+	}
+	if !reflect.DeepEqual(infos, want) {
+		t.Fatalf("got %+v, want %+v", infos, want)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := listChecks(buf, infos, "json"); err != nil {
+		t.Fatal(err)
+	}
+	var got []checkInfo
+	dec := json.NewDecoder(buf)
+	for dec.More() {
+		var c checkInfo
+		if err := dec.Decode(&c); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, c)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	if err := listChecks(buf, infos, "sarif"); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+}
+
+func TestExplainChecks(t *testing.T) {
+	cs := lint.InitializeAnalyzers(map[string]*lint.Documentation{
+		"XX9999": {
+			Title:   "Some check",
+			Text:    "Some text",
+			Before:  "x := 1",
+			After:   "y := 1",
+			Related: []string{"XX9998"},
+			Since:   "2020.1",
+		},
+	}, map[string]*analysis.Analyzer{
+		"XX9999": {Name: "XX9999"},
+	})
+	checks := append([]*analysis.Analyzer{{Name: "XX9998", Doc: "Other check\n\nMore text"}}, cs["XX9999"])
+
+	if got := findChecks(checks, "XX9999"); len(got) != 1 || got[0].Name != "XX9999" {
+		t.Errorf("unexpected checks %v", got)
+	}
+	if got := findChecks(checks, "XX*"); len(got) != 2 || got[0].Name != "XX9998" {
+		t.Errorf("unexpected checks %v", got)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := explainChecks(buf, findChecks(checks, "XX*"), "json"); err != nil {
+		t.Fatal(err)
+	}
+	dec := json.NewDecoder(buf)
+	var docs []checkDoc
+	for dec.More() {
+		var doc checkDoc
+		if err := dec.Decode(&doc); err != nil {
+			t.Fatal(err)
+		}
+		docs = append(docs, doc)
+	}
+	want := []checkDoc{
+		{Name: "XX9998", Title: "Other check", Text: "More text", Default: true},
+		{Name: "XX9999", Title: "Some check", Text: "Some text", Before: "x := 1", After: "y := 1", Related: []string{"XX9998"}, Since: "2020.1", Default: true},
+	}
+	if !reflect.DeepEqual(docs, want) {
+		t.Errorf("got %+v, want %+v", docs, want)
+	}
+
+	buf.Reset()
+	if err := explainChecks(buf, findChecks(checks, "XX9999"), "markdown"); err != nil {
+		t.Fatal(err)
+	}
+	wantMarkdown := "## XX9999 - Some check\n\nSome text\n\nBefore:\n\n```go\nx := 1\n```\n\nAfter:\n\n```go\ny := 1\n```\n\n" +
+		"**Related checks:** [XX9998](#XX9998)\n\n**Available since:** 2020.1\n"
+	if got := buf.String(); got != wantMarkdown {
+		t.Errorf("got\n%s\nwant\n%s", got, wantMarkdown)
+	}
+}
+
+func TestByteSize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want uint64
+		str  string
+	}{
+		{"0", 0, "0B"},
+		{"1024", 1024, "1.0KiB"},
+		{"512MiB", 512 << 20, "512.0MiB"},
+		{"1.5GiB", 3 << 29, "1.5GiB"},
+		{"2GB", 2e9, "1.9GiB"},
+		{"4G", 4 << 30, "4.0GiB"},
+		{"100 KB", 100e3, "97.7KiB"},
+		{"10B", 10, "10B"},
+	}
+	for _, tt := range tests {
+		var b byteSize
+		if err := b.Set(tt.in); err != nil {
+			t.Errorf("Set(%q) failed: %s", tt.in, err)
+			continue
+		}
+		if uint64(b) != tt.want {
+			t.Errorf("Set(%q) = %d, want %d", tt.in, uint64(b), tt.want)
+		}
+		if s := b.String(); s != tt.str {
+			t.Errorf("%q: String() = %q, want %q", tt.in, s, tt.str)
+		}
+	}
+	for _, in := range []string{"", "GiB", "-1GiB", "1XB"} {
+		var b byteSize
+		if err := b.Set(in); err == nil {
+			t.Errorf("Set(%q) succeeded, want error", in)
+		}
+	}
+}
@@ -1,55 +1,128 @@
 package lintcmd
 
 import (
+	"fmt"
+	"regexp"
 	"strings"
+	"time"
 
 	"honnef.co/go/tools/lintcmd/runner"
 )
 
-func parseDirectives(dirs []runner.SerializedDirective) ([]ignore, []problem) {
+// untilLayout is the layout of the optional until=<date> argument of
+// ignore directives.
+const untilLayout = "2006-01-02"
+
+// parseDirectives turns linter directives into ignores. Directives
+// that have expired or are malformed don't result in ignores and are
+// returned as inactive directives instead. Expired and malformed
+// directives, as well as directives whose reasons don't match reason,
+// are reported as problems. reason may be nil.
+func parseDirectives(dirs []runner.SerializedDirective, reason *regexp.Regexp, now time.Time) ([]ignore, []inactiveDirective, []problem) {
 	var ignores []ignore
+	var inactive []inactiveDirective
 	var problems []problem
 
 	for _, dir := range dirs {
 		cmd := dir.Command
 		args := dir.Arguments
 		switch cmd {
-		case "ignore", "file-ignore":
-			if len(args) < 2 {
-				p := problem{
-					Diagnostic: runner.Diagnostic{
-						Position: dir.NodePosition,
-						Message:  "malformed linter directive; missing the required reason field?",
-						Category: "compile",
-					},
-					Severity: severityError,
-				}
-				problems = append(problems, p)
-				continue
-			}
+		case "ignore", "ignore-block", "file-ignore", "package-ignore":
 		default:
 			// unknown directive, ignore
 			continue
 		}
-		checks := strings.Split(args[0], ",")
+
 		pos := dir.NodePosition
+		id := ignoreDirective{
+			File:    pos.Filename,
+			Pos:     dir.DirectivePosition,
+			Command: cmd,
+		}
+		malformed := func(msg string) {
+			p := problem{
+				Diagnostic: runner.Diagnostic{
+					Position: dir.NodePosition,
+					Message:  msg,
+					Category: "compile",
+				},
+				Severity: severityError,
+			}
+			problems = append(problems, p)
+			inactive = append(inactive, inactiveDirective{id, stateMalformed})
+		}
+
+		if len(args) > 0 {
+			id.Checks = strings.Split(args[0], ",")
+		}
+		if len(args) < 2 {
+			malformed("malformed linter directive; missing the required reason field?")
+			continue
+		}
+		args = args[1:]
+
+		var until time.Time
+		if strings.HasPrefix(args[0], "until=") {
+			id.Until = strings.TrimPrefix(args[0], "until=")
+			var err error
+			until, err = time.Parse(untilLayout, id.Until)
+			if err != nil {
+				malformed(fmt.Sprintf("malformed linter directive; %q is not a date of the form YYYY-MM-DD", id.Until))
+				continue
+			}
+			args = args[1:]
+			if len(args) == 0 {
+				malformed("malformed linter directive; missing the required reason field?")
+				continue
+			}
+		}
+
+		id.Reason = strings.Join(args, " ")
+		if reason != nil && !reason.MatchString(id.Reason) {
+			malformed(fmt.Sprintf("malformed linter directive; the reason must match %q", reason))
+			continue
+		}
+		if cmd == "package-ignore" && (!dir.FileNode || dir.DirectivePosition.Line >= dir.NodePosition.Line) {
+			malformed("malformed linter directive; package-ignore must be part of the package's documentation")
+			continue
+		}
+		if id.Until != "" && !now.Before(until) {
+			p := problem{
+				Diagnostic: runner.Diagnostic{
+					Position: dir.DirectivePosition,
+					Message:  fmt.Sprintf("this linter directive expired on %s and no longer applies", id.Until),
+					Category: "staticcheck",
+				},
+			}
+			problems = append(problems, p)
+			inactive = append(inactive, inactiveDirective{id, stateExpired})
+			continue
+		}
+
 		var ig ignore
 		switch cmd {
 		case "ignore":
 			ig = &lineIgnore{
-				File:   pos.Filename,
-				Line:   pos.Line,
-				Checks: checks,
-				Pos:    dir.DirectivePosition,
+				ignoreDirective: id,
+				Line:            pos.Line,
+			}
+		case "ignore-block":
+			ig = &blockIgnore{
+				ignoreDirective: id,
+				StartLine:       pos.Line,
+				EndLine:         dir.NodeEnd.Line,
 			}
 		case "file-ignore":
 			ig = &fileIgnore{
-				File:   pos.Filename,
-				Checks: checks,
+				ignoreDirective: id,
+			}
+		case "package-ignore":
+			ig = &packageIgnore{
+				ignoreDirective: id,
 			}
 		}
 		ignores = append(ignores, ig)
 	}
 
-	return ignores, problems
+	return ignores, inactive, problems
 }
@@ -0,0 +1,133 @@
+package lintcmd
+
+import (
+	"go/token"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"honnef.co/go/tools/lintcmd/runner"
+)
+
+func TestParseDirectives(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	dir := func(line int, text string) runner.SerializedDirective {
+		fields := strings.Split(text, " ")
+		return runner.SerializedDirective{
+			Command:           fields[0],
+			Arguments:         fields[1:],
+			DirectivePosition: token.Position{Filename: "a.go", Line: line},
+			NodePosition:      token.Position{Filename: "a.go", Line: line + 1},
+		}
+	}
+
+	tests := []struct {
+		text    string
+		reason  *regexp.Regexp
+		ignore  bool
+		problem string
+	}{
+		{text: "ignore SA1000 reason", ignore: true},
+		{text: "ignore SA1000", problem: "missing the required reason field"},
+		{text: "ignore SA1000 until=2027-01-01 reason", ignore: true},
+		{text: "file-ignore SA1000 until=2027-01-01 reason", ignore: true},
+		{text: "ignore SA1000 until=2026-06-01 reason", problem: "expired on 2026-06-01"},
+		{text: "ignore SA1000 until=2027-01-01", problem: "missing the required reason field"},
+		{text: "ignore SA1000 until=01/01/2027 reason", problem: "not a date of the form YYYY-MM-DD"},
+		{text: "ignore SA1000 see ABC-123", reason: regexp.MustCompile(`[A-Z]+-\d+`), ignore: true},
+		{text: "ignore SA1000 until=2027-01-01 ABC-123", reason: regexp.MustCompile(`[A-Z]+-\d+`), ignore: true},
+		{text: "ignore SA1000 no ticket", reason: regexp.MustCompile(`[A-Z]+-\d+`), problem: "the reason must match"},
+		{text: "ignore-block SA1000 reason", ignore: true},
+		{text: "package-ignore SA1000 reason", problem: "must be part of the package's documentation"},
+		{text: "unknown SA1000"},
+	}
+	for _, tt := range tests {
+		ignores, inactive, problems := parseDirectives([]runner.SerializedDirective{dir(1, tt.text)}, tt.reason, now)
+		if got := len(ignores) == 1; got != tt.ignore {
+			t.Errorf("%q: got %d ignores, want ignore = %t", tt.text, len(ignores), tt.ignore)
+		}
+		// Every known directive is either active or inactive.
+		if got := len(ignores) + len(inactive); got != 1 && tt.text != "unknown SA1000" {
+			t.Errorf("%q: got %d ignores and %d inactive directives, want one in total", tt.text, len(ignores), len(inactive))
+		}
+		if tt.problem == "" {
+			if len(problems) != 0 {
+				t.Errorf("%q: got unexpected problems %v", tt.text, problems)
+			}
+			continue
+		}
+		if len(problems) != 1 || !strings.Contains(problems[0].Message, tt.problem) {
+			t.Errorf("%q: got problems %v, want one containing %q", tt.text, problems, tt.problem)
+			continue
+		}
+		wantState := stateMalformed
+		if strings.Contains(tt.problem, "expired") {
+			wantState = stateExpired
+		}
+		if len(inactive) != 1 || inactive[0].State != wantState || inactive[0].Directive.Pos.Line != 1 {
+			t.Errorf("%q: got inactive directives %v, want one that is %s", tt.text, inactive, wantState)
+		}
+		if strings.Contains(tt.problem, "expired") {
+			if problems[0].Category != "staticcheck" || problems[0].Position.Line != 1 {
+				t.Errorf("%q: expired directive reported as %s at %s", tt.text, problems[0].Category, problems[0].Position)
+			}
+		} else if problems[0].Category != "compile" {
+			t.Errorf("%q: got category %s, want compile", tt.text, problems[0].Category)
+		}
+	}
+}
+
+func TestIgnoreScopes(t *testing.T) {
+	dirs := []runner.SerializedDirective{
+		{
+			Command:           "ignore-block",
+			Arguments:         []string{"SA1000", "reason"},
+			DirectivePosition: token.Position{Filename: "a.go", Line: 3},
+			NodePosition:      token.Position{Filename: "a.go", Line: 4},
+			NodeEnd:           token.Position{Filename: "a.go", Line: 10},
+		},
+		{
+			Command:           "package-ignore",
+			Arguments:         []string{"SA2000", "reason"},
+			DirectivePosition: token.Position{Filename: "doc.go", Line: 1},
+			NodePosition:      token.Position{Filename: "doc.go", Line: 2},
+			NodeEnd:           token.Position{Filename: "doc.go", Line: 2},
+			FileNode:          true,
+		},
+	}
+	ignores, _, problems := parseDirectives(dirs, nil, time.Now())
+	if len(ignores) != 2 || len(problems) != 0 {
+		t.Fatalf("got %d ignores and problems %v, want 2 ignores and no problems", len(ignores), problems)
+	}
+	block, pkg := ignores[0], ignores[1]
+
+	prob := func(file string, line int, check string) problem {
+		return problem{Diagnostic: runner.Diagnostic{
+			Position: token.Position{Filename: file, Line: line},
+			Category: check,
+		}}
+	}
+	tests := []struct {
+		ig   ignore
+		p    problem
+		want bool
+	}{
+		{block, prob("a.go", 4, "SA1000"), true},
+		{block, prob("a.go", 10, "SA1000"), true},
+		{block, prob("a.go", 11, "SA1000"), false},
+		{block, prob("a.go", 3, "SA1000"), false},
+		{block, prob("b.go", 5, "SA1000"), false},
+		{block, prob("a.go", 5, "SA1001"), false},
+		{pkg, prob("b.go", 100, "SA2000"), true},
+		{pkg, prob("b.go", 100, "SA1000"), false},
+	}
+	for _, tt := range tests {
+		if got := tt.ig.Match(tt.p); got != tt.want {
+			t.Errorf("%T.Match(%s:%d %s) = %t, want %t", tt.ig, tt.p.Position.Filename, tt.p.Position.Line, tt.p.Category, got, tt.want)
+		}
+	}
+	if !block.directive().Matched || !pkg.directive().Matched {
+		t.Errorf("ignores weren't marked as matched")
+	}
+}
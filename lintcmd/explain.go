@@ -0,0 +1,153 @@
+package lintcmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"honnef.co/go/tools/analysis/lint"
+	"honnef.co/go/tools/config"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// checkDoc is the documentation of a check, as printed by -explain
+// -f json.
+type checkDoc struct {
+	Name              string   `json:"name"`
+	Title             string   `json:"title"`
+	Text              string   `json:"text,omitempty"`
+	Before            string   `json:"before,omitempty"`
+	After             string   `json:"after,omitempty"`
+	SeverityRationale string   `json:"severity_rationale,omitempty"`
+	Related           []string `json:"related,omitempty"`
+	Since             string   `json:"since,omitempty"`
+	Default           bool     `json:"default"`
+	Options           []string `json:"options,omitempty"`
+}
+
+func documentCheck(c *analysis.Analyzer) checkDoc {
+	title, text := docTitle(c)
+	doc := lint.DocumentationOf(c)
+	if doc == nil {
+		return checkDoc{Name: c.Name, Title: title, Text: text, Default: true}
+	}
+	cd := checkDoc{
+		Name:              c.Name,
+		Title:             title,
+		Text:              text,
+		Before:            doc.Before,
+		After:             doc.After,
+		SeverityRationale: doc.SeverityRationale,
+		Related:           doc.Related,
+		Since:             doc.Since,
+		Default:           !doc.NonDefault,
+		Options:           doc.Options,
+	}
+	if doc.CheckOptions != nil {
+		for _, opt := range config.DescribeOptions(doc.CheckOptions) {
+			cd.Options = append(cd.Options, fmt.Sprintf("options.%s.%s", c.Name, opt.Name))
+		}
+	}
+	return cd
+}
+
+// findChecks returns the checks matched by the comma-separated list of
+// patterns, which use the same syntax as the checks option, sorted by
+// name.
+func findChecks(cs []*analysis.Analyzer, patterns string) []*analysis.Analyzer {
+	names := make([]string, len(cs))
+	for i, c := range cs {
+		names[i] = c.Name
+	}
+	matched := filterAnalyzerNames(names, strings.Split(patterns, ","))
+	var out []*analysis.Analyzer
+	for _, c := range cs {
+		if matched[c.Name] {
+			out = append(out, c)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// explainChecks prints the documentation of checks in the given
+// format, which is one of "text", "json" or "markdown". Like the JSON
+// formatter, the JSON format is a stream of objects, one per check.
+func explainChecks(w io.Writer, checks []*analysis.Analyzer, format string) error {
+	switch format {
+	case "text":
+		for i, c := range checks {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			if len(checks) > 1 {
+				fmt.Fprintf(w, "%s: ", c.Name)
+			}
+			fmt.Fprintln(w, c.Doc)
+		}
+		return nil
+	case "json":
+		enc := json.NewEncoder(w)
+		for _, c := range checks {
+			if err := enc.Encode(documentCheck(c)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "markdown":
+		for i, c := range checks {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			writeMarkdown(w, documentCheck(c))
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported output format %q for -explain", format)
+	}
+}
+
+func writeMarkdown(w io.Writer, doc checkDoc) {
+	fmt.Fprintf(w, "## %s - %s\n\n", doc.Name, doc.Title)
+	if doc.Text != "" {
+		// Code in Text is indented by four spaces, which Markdown
+		// renders as code blocks.
+		fmt.Fprintf(w, "%s\n\n", doc.Text)
+	}
+	if doc.Before != "" {
+		fmt.Fprintf(w, "Before:\n\n```go\n%s\n```\n\n", doc.Before)
+	}
+	if doc.After != "" {
+		fmt.Fprintf(w, "After:\n\n```go\n%s\n```\n\n", doc.After)
+	}
+	if doc.SeverityRationale != "" {
+		fmt.Fprintf(w, "**Severity:** %s\n\n", doc.SeverityRationale)
+	}
+	if len(doc.Related) > 0 {
+		links := make([]string, len(doc.Related))
+		for i, r := range doc.Related {
+			links[i] = fmt.Sprintf("[%s](#%s)", r, r)
+		}
+		fmt.Fprintf(w, "**Related checks:** %s\n\n", strings.Join(links, ", "))
+	}
+	since := doc.Since
+	if since == "" {
+		since = "unreleased"
+	}
+	fmt.Fprintf(w, "**Available since:** %s", since)
+	if !doc.Default {
+		fmt.Fprint(w, ", non-default")
+	}
+	fmt.Fprintln(w)
+	if len(doc.Options) > 0 {
+		fmt.Fprint(w, "\n**Options:**\n\n")
+		for _, opt := range doc.Options {
+			fmt.Fprintf(w, "- `%s`\n", opt)
+		}
+	}
+}
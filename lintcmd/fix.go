@@ -0,0 +1,177 @@
+package lintcmd
+
+import (
+	"bytes"
+	"fmt"
+	"go/token"
+	"io/ioutil"
+	"sort"
+)
+
+// fixedFile is the result of applying suggested fixes to a file.
+type fixedFile struct {
+	Name string
+	Old  []byte
+	New  []byte
+}
+
+type byteEdit struct {
+	start, end int
+	text       []byte
+}
+
+func (e byteEdit) equal(o byteEdit) bool {
+	return e.start == o.start && e.end == o.end && bytes.Equal(e.text, o.text)
+}
+
+func (e byteEdit) overlaps(o byteEdit) bool {
+	if e.start == e.end && o.start == o.end {
+		// Two insertions conflict if they insert at the same offset,
+		// because we can't know in which order they should go.
+		return e.start == o.start
+	}
+	return e.start < o.end && o.start < e.end
+}
+
+type fileEdits struct {
+	content []byte
+	// lines holds the offsets of the beginnings of lines
+	lines []int
+	edits []byteEdit
+}
+
+// offset validates that pos describes a valid position in the file
+// and returns its offset. Positions of edits may have been adjusted by
+// line directives, in which case they can't be used for editing the
+// file.
+func (f *fileEdits) offset(pos token.Position) (int, bool) {
+	if pos.Line < 1 || pos.Line > len(f.lines) || pos.Offset > len(f.content) {
+		return 0, false
+	}
+	if f.lines[pos.Line-1]+pos.Column-1 != pos.Offset {
+		return 0, false
+	}
+	return pos.Offset, true
+}
+
+// computeFixes applies the first suggested fix of each problem in
+// memory, returning the modified files sorted by name. For each
+// problem, it reports whether its fix has been applied.
+//
+// Fixes are applied atomically: if any of a fix's edits overlaps with
+// an edit of an earlier fix, the entire fix will be skipped and a
+// warning will be returned. Edits that are identical to previously
+// applied ones, such as those caused by a file belonging to multiple
+// packages, are only applied once.
+func computeFixes(ps []problem) ([]fixedFile, []bool, []string, error) {
+	files := map[string]*fileEdits{}
+	applied := make([]bool, len(ps))
+	var warnings []string
+
+	load := func(name string) (*fileEdits, error) {
+		if f, ok := files[name]; ok {
+			return f, nil
+		}
+		b, err := ioutil.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		f := &fileEdits{content: b, lines: []int{0}}
+		for i, c := range b {
+			if c == '\n' {
+				f.lines = append(f.lines, i+1)
+			}
+		}
+		files[name] = f
+		return f, nil
+	}
+
+	for i, p := range ps {
+		if p.Severity == severityIgnored || len(p.SuggestedFixed) == 0 {
+			continue
+		}
+		fix := p.SuggestedFixed[0]
+
+		type pendingEdit struct {
+			file *fileEdits
+			edit byteEdit
+		}
+		var pending []pendingEdit
+		ok := true
+		for _, edit := range fix.TextEdits {
+			if edit.Position.Filename == "" || edit.Position.Filename != edit.End.Filename {
+				ok = false
+				break
+			}
+			f, err := load(edit.Position.Filename)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			start, ok1 := f.offset(edit.Position)
+			end, ok2 := f.offset(edit.End)
+			if !ok1 || !ok2 || start > end {
+				ok = false
+				break
+			}
+			pending = append(pending, pendingEdit{f, byteEdit{start, end, edit.NewText}})
+		}
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("%s: skipped fix %q (%s): edits don't match the file on disk",
+				relativePositionString(p.Position), fix.Message, p.Category))
+			continue
+		}
+
+		var conflict bool
+		var fresh []pendingEdit
+	editLoop:
+		for _, pe := range pending {
+			for _, other := range pe.file.edits {
+				if pe.edit.equal(other) {
+					// Already applied via another problem
+					continue editLoop
+				}
+				if pe.edit.overlaps(other) {
+					conflict = true
+					break editLoop
+				}
+			}
+			fresh = append(fresh, pe)
+		}
+		if conflict {
+			warnings = append(warnings, fmt.Sprintf("%s: skipped fix %q (%s): conflicts with another fix",
+				relativePositionString(p.Position), fix.Message, p.Category))
+			continue
+		}
+		for _, pe := range fresh {
+			pe.file.edits = append(pe.file.edits, pe.edit)
+		}
+		applied[i] = true
+	}
+
+	out := make([]fixedFile, 0, len(files))
+	for name, f := range files {
+		if len(f.edits) == 0 {
+			continue
+		}
+		sort.SliceStable(f.edits, func(i, j int) bool {
+			return f.edits[i].start < f.edits[j].start
+		})
+		buf := bytes.NewBuffer(make([]byte, 0, len(f.content)))
+		last := 0
+		for _, edit := range f.edits {
+			buf.Write(f.content[last:edit.start])
+			buf.Write(edit.text)
+			last = edit.end
+		}
+		buf.Write(f.content[last:])
+		out = append(out, fixedFile{
+			Name: name,
+			Old:  f.content,
+			New:  buf.Bytes(),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Name < out[j].Name
+	})
+	return out, applied, warnings, nil
+}
@@ -0,0 +1,74 @@
+package lintcmd
+
+import (
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"honnef.co/go/tools/lintcmd/runner"
+)
+
+func TestComputeFixes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "staticcheck")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "pkg.go")
+	const src = "package pkg\n\nvar x = a + b\n"
+	if err := ioutil.WriteFile(file, []byte(src), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	pos := func(off int) token.Position {
+		line, col := 1, 1
+		for _, c := range src[:off] {
+			if c == '\n' {
+				line++
+				col = 1
+			} else {
+				col++
+			}
+		}
+		return token.Position{Filename: file, Offset: off, Line: line, Column: col}
+	}
+	fix := func(check string, start, end int, text string) problem {
+		return problem{Diagnostic: runner.Diagnostic{
+			Position: pos(start),
+			Category: check,
+			SuggestedFixed: []runner.SuggestedFix{{
+				Message:   "fix",
+				TextEdits: []runner.TextEdit{{Position: pos(start), End: pos(end), NewText: []byte(text)}},
+			}},
+		}}
+	}
+
+	ps := []problem{
+		// replace a
+		fix("S1000", 21, 22, "c"),
+		// the same edit, as reported by another package variant
+		fix("S1000", 21, 22, "c"),
+		// overlaps with the first edit
+		fix("S1001", 21, 26, "d"),
+		// replace b
+		fix("S1002", 25, 26, "e"),
+	}
+	files, applied, warnings, err := computeFixes(ps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []bool{true, true, false, true}; len(applied) != len(want) || applied[0] != want[0] || applied[1] != want[1] || applied[2] != want[2] || applied[3] != want[3] {
+		t.Errorf("got applied %v, want %v", applied, want)
+	}
+	if len(warnings) != 1 {
+		t.Errorf("got %d warnings, want 1", len(warnings))
+	}
+	if len(files) != 1 {
+		t.Fatalf("got %d files, want 1", len(files))
+	}
+	if got, want := string(files[0].New), "package pkg\n\nvar x = c + e\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -1,13 +1,27 @@
 package lintcmd
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
 	"go/token"
 	"io"
+	"io/ioutil"
+	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"text/tabwriter"
+	"unicode/utf16"
+
+	"honnef.co/go/tools/analysis/lint"
+	"honnef.co/go/tools/lintcmd/version"
+
+	"golang.org/x/tools/go/analysis"
 )
 
 func shortPath(path string) string {
@@ -36,13 +50,22 @@ func relativePositionString(pos token.Position) string {
 }
 
 type statter interface {
-	Stats(total, errors, warnings, ignored int)
+	Stats(total, errors, warnings, ignored, baselined int)
 }
 
 type formatter interface {
 	Format(p problem)
 }
 
+// A documentFormatter renders all problems as a single document,
+// as opposed to a stream of individual problems. It buffers the
+// problems passed to Format and writes its output once Finish is
+// called at the end of the run.
+type documentFormatter interface {
+	formatter
+	Finish()
+}
+
 type documentationMentioner interface {
 	MentionCheckDocumentation(cmd string)
 }
@@ -52,7 +75,12 @@ type textFormatter struct {
 }
 
 func (o textFormatter) Format(p problem) {
-	fmt.Fprintf(o.W, "%s: %s\n", relativePositionString(p.Position), p.String())
+	switch p.Severity {
+	case severityInfo, severityHint:
+		fmt.Fprintf(o.W, "%s: %s: %s\n", relativePositionString(p.Position), p.Severity, p.String())
+	default:
+		fmt.Fprintf(o.W, "%s: %s\n", relativePositionString(p.Position), p.String())
+	}
 	for _, r := range p.Related {
 		fmt.Fprintf(o.W, "\t%s: %s\n", relativePositionString(r.Position), r.Message)
 	}
@@ -139,7 +167,12 @@ func (o *stylishFormatter) Format(p problem) {
 		o.prevFile = pos.Filename
 		o.tw = tabwriter.NewWriter(o.W, 0, 4, 2, ' ', 0)
 	}
-	fmt.Fprintf(o.tw, "  (%d, %d)\t%s\t%s\n", pos.Line, pos.Column, p.Category, p.Message)
+	check := p.Category
+	switch p.Severity {
+	case severityInfo, severityHint:
+		check = fmt.Sprintf("%s (%s)", p.Category, p.Severity)
+	}
+	fmt.Fprintf(o.tw, "  (%d, %d)\t%s\t%s\n", pos.Line, pos.Column, check, p.Message)
 	for _, r := range p.Related {
 		fmt.Fprintf(o.tw, "    (%d, %d)\t\t  %s\n", r.Position.Line, r.Position.Column, r.Message)
 	}
@@ -149,11 +182,575 @@ func (o *stylishFormatter) MentionCheckDocumentation(cmd string) {
 	textFormatter{W: o.W}.MentionCheckDocumentation(cmd)
 }
 
-func (o *stylishFormatter) Stats(total, errors, warnings, ignored int) {
+func (o *stylishFormatter) Stats(total, errors, warnings, ignored, baselined int) {
 	if o.tw != nil {
 		o.tw.Flush()
 		fmt.Fprintln(o.W)
 	}
-	fmt.Fprintf(o.W, " ✖ %d problems (%d errors, %d warnings, %d ignored)\n",
-		total, errors, warnings, ignored)
+	if baselined > 0 {
+		fmt.Fprintf(o.W, " ✖ %d problems (%d errors, %d warnings, %d ignored, %d baselined)\n",
+			total, errors, warnings, ignored, baselined)
+	} else {
+		fmt.Fprintf(o.W, " ✖ %d problems (%d errors, %d warnings, %d ignored)\n",
+			total, errors, warnings, ignored)
+	}
+}
+
+type sarifFormatter struct {
+	W      io.Writer
+	Checks []*analysis.Analyzer
+
+	results []sarifResult
+	// lines caches the lines of source files, for converting byte
+	// offsets to UTF-16 columns.
+	lines map[string][][]byte
+}
+
+func (o *sarifFormatter) Format(p problem) {
+	r := sarifResult{
+		RuleID:  p.Category,
+		Level:   "error",
+		Message: sarifMessage{Text: p.Message},
+	}
+	switch p.Severity {
+	case severityWarning:
+		r.Level = "warning"
+	case severityInfo:
+		r.Level = "note"
+	case severityHint:
+		r.Level = "none"
+	case severityIgnored:
+		// Ignored problems are only formatted when -show-ignored was
+		// provided. They were suppressed by a linter directive.
+		r.Level = "warning"
+		r.Suppressions = []sarifSuppression{{Kind: "inSource"}}
+	case severityBaselined:
+		// Baselined problems are only formatted when -show-ignored
+		// was provided.
+		r.Level = "warning"
+		r.BaselineState = "unchanged"
+		r.Suppressions = []sarifSuppression{{Kind: "external"}}
+	}
+	if p.Position.IsValid() {
+		r.Locations = []sarifLocation{{
+			PhysicalLocation: o.physicalLocation(p.Position, p.End),
+		}}
+	}
+	for i, rel := range p.Related {
+		if !rel.Position.IsValid() {
+			continue
+		}
+		r.RelatedLocations = append(r.RelatedLocations, sarifLocation{
+			ID:               i + 1,
+			PhysicalLocation: o.physicalLocation(rel.Position, rel.End),
+			Message:          &sarifMessage{Text: rel.Message},
+		})
+	}
+	for _, fix := range p.SuggestedFixed {
+		sfix := sarifFix{
+			Description: sarifMessage{Text: fix.Message},
+		}
+		// SARIF groups replacements by the file they apply to.
+		changes := map[string]int{}
+		for _, edit := range fix.TextEdits {
+			idx, ok := changes[edit.Position.Filename]
+			if !ok {
+				idx = len(sfix.ArtifactChanges)
+				changes[edit.Position.Filename] = idx
+				sfix.ArtifactChanges = append(sfix.ArtifactChanges, sarifArtifactChange{
+					ArtifactLocation: o.artifactLocation(edit.Position.Filename),
+				})
+			}
+			sfix.ArtifactChanges[idx].Replacements = append(sfix.ArtifactChanges[idx].Replacements, sarifReplacement{
+				DeletedRegion:   o.region(edit.Position, edit.End),
+				InsertedContent: sarifArtifactContent{Text: string(edit.NewText)},
+			})
+		}
+		r.Fixes = append(r.Fixes, sfix)
+	}
+	o.results = append(o.results, r)
+}
+
+func (o *sarifFormatter) Finish() {
+	cwd, _ := os.Getwd()
+	checks := make([]*analysis.Analyzer, len(o.Checks))
+	copy(checks, o.Checks)
+	sort.Slice(checks, func(i, j int) bool {
+		return checks[i].Name < checks[j].Name
+	})
+
+	driver := sarifDriver{
+		Name:           "Staticcheck",
+		InformationURI: "https://staticcheck.io",
+	}
+	if version.Version != "devel" {
+		driver.Version = version.Version
+	}
+	for _, c := range checks {
+		driver.Rules = append(driver.Rules, sarifRuleFor(c))
+	}
+
+	run := sarifRun{
+		Tool: sarifTool{Driver: driver},
+		Invocations: []sarifInvocation{{
+			ExecutionSuccessful: true,
+			WorkingDirectory:    sarifArtifactLocation{URI: fileURI(cwd)},
+		}},
+		OriginalURIBaseIDs: map[string]sarifArtifactLocation{
+			sarifSrcRoot: {URI: strings.TrimSuffix(fileURI(cwd), "/") + "/"},
+		},
+		ColumnKind: "utf16CodeUnits",
+		Results:    o.results,
+	}
+	if run.Results == nil {
+		// SARIF requires an empty array, not null, if there are no
+		// results.
+		run.Results = []sarifResult{}
+	}
+	log := sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []sarifRun{run},
+	}
+	_ = json.NewEncoder(o.W).Encode(log)
+}
+
+func sarifRuleFor(c *analysis.Analyzer) sarifRule {
+	rule := sarifRule{
+		ID:      c.Name,
+		HelpURI: "https://staticcheck.io/docs/checks#" + c.Name,
+		DefaultConfiguration: sarifReportingConfiguration{
+			Enabled: true,
+			Level:   "error",
+		},
+	}
+	title, text := docTitle(c)
+	rule.ShortDescription = sarifMessage{Text: title}
+	if text != "" {
+		rule.FullDescription = &sarifMessage{Text: text}
+	}
+	doc := lint.DocumentationOf(c)
+	if doc == nil {
+		return rule
+	}
+	rule.DefaultConfiguration.Enabled = !doc.NonDefault
+	rule.Properties = sarifRuleProperties{
+		Since:      doc.Since,
+		NonDefault: doc.NonDefault,
+	}
+	return rule
 }
+
+func (o *sarifFormatter) physicalLocation(pos, end token.Position) sarifPhysicalLocation {
+	region := o.region(pos, end)
+	return sarifPhysicalLocation{
+		ArtifactLocation: o.artifactLocation(pos.Filename),
+		Region:           &region,
+	}
+}
+
+func (o *sarifFormatter) region(pos, end token.Position) sarifRegion {
+	r := sarifRegion{
+		StartLine:   pos.Line,
+		StartColumn: o.column(pos),
+	}
+	if end.IsValid() && end.Filename == pos.Filename {
+		r.EndLine = end.Line
+		r.EndColumn = o.column(end)
+	}
+	return r
+}
+
+// column converts the byte-based column of pos to a column counted in
+// UTF-16 code units, as required by SARIF. If the source file cannot
+// be read, the byte-based column is returned unchanged.
+func (o *sarifFormatter) column(pos token.Position) int {
+	if pos.Column == 0 {
+		return 0
+	}
+	if o.lines == nil {
+		o.lines = map[string][][]byte{}
+	}
+	lines, ok := o.lines[pos.Filename]
+	if !ok {
+		if b, err := ioutil.ReadFile(pos.Filename); err == nil {
+			lines = bytes.Split(b, []byte("\n"))
+		}
+		o.lines[pos.Filename] = lines
+	}
+	if pos.Line < 1 || pos.Line > len(lines) {
+		return pos.Column
+	}
+	line := lines[pos.Line-1]
+	if pos.Column-1 > len(line) {
+		return pos.Column
+	}
+	return len(utf16.Encode([]rune(string(line[:pos.Column-1])))) + 1
+}
+
+func (o *sarifFormatter) artifactLocation(path string) sarifArtifactLocation {
+	if cwd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(cwd, path); err == nil && !strings.HasPrefix(rel, "..") {
+			u := &url.URL{Path: filepath.ToSlash(rel)}
+			return sarifArtifactLocation{
+				URI:       u.String(),
+				URIBaseID: sarifSrcRoot,
+			}
+		}
+	}
+	return sarifArtifactLocation{URI: fileURI(path)}
+}
+
+func fileURI(path string) string {
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		// Windows paths such as C:/foo
+		path = "/" + path
+	}
+	u := &url.URL{Scheme: "file", Path: path}
+	return u.String()
+}
+
+// xmlSeverity returns the name of p's severity, as used by
+// Checkstyle and our JUnit output.
+func xmlSeverity(p problem) string {
+	switch p.Severity {
+	case severityIgnored, severityBaselined:
+		return "ignore"
+	case severityHint:
+		// Checkstyle doesn't have a lower severity
+		return "info"
+	default:
+		return p.Severity.String()
+	}
+}
+
+// messageWithRelated returns p's message, followed by its related
+// information on separate lines.
+func messageWithRelated(p problem) string {
+	msg := p.Message
+	for _, r := range p.Related {
+		msg += fmt.Sprintf("\n%s: %s", relativePositionString(r.Position), r.Message)
+	}
+	return msg
+}
+
+type checkstyleFormatter struct {
+	W io.Writer
+
+	files []checkstyleFile
+	// index of each file in files
+	indices map[string]int
+}
+
+type checkstyleFile struct {
+	Name   string            `xml:"name,attr"`
+	Errors []checkstyleError `xml:"error"`
+}
+
+type checkstyleError struct {
+	Line     int    `xml:"line,attr"`
+	Column   int    `xml:"column,attr,omitempty"`
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+func (o *checkstyleFormatter) Format(p problem) {
+	if o.indices == nil {
+		o.indices = map[string]int{}
+	}
+	idx, ok := o.indices[p.Position.Filename]
+	if !ok {
+		idx = len(o.files)
+		o.indices[p.Position.Filename] = idx
+		o.files = append(o.files, checkstyleFile{Name: p.Position.Filename})
+	}
+	o.files[idx].Errors = append(o.files[idx].Errors, checkstyleError{
+		Line:     p.Position.Line,
+		Column:   p.Position.Column,
+		Severity: xmlSeverity(p),
+		Message:  messageWithRelated(p),
+		Source:   "staticcheck." + p.Category,
+	})
+}
+
+func (o *checkstyleFormatter) Finish() {
+	doc := struct {
+		XMLName xml.Name         `xml:"checkstyle"`
+		Version string           `xml:"version,attr"`
+		Files   []checkstyleFile `xml:"file"`
+	}{
+		Version: "4.3",
+		Files:   o.files,
+	}
+	fmt.Fprint(o.W, xml.Header)
+	enc := xml.NewEncoder(o.W)
+	enc.Indent("", "  ")
+	_ = enc.Encode(doc)
+	fmt.Fprintln(o.W)
+}
+
+// junitFormatter emits one test suite per package and one test case
+// per problem. Checks don't have a notion of passing tests, so every
+// test case is either a failure or, for ignored problems, skipped.
+type junitFormatter struct {
+	W io.Writer
+
+	suites map[string]*junitTestSuite
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Error     *junitFailure `xml:"error,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+func (o *junitFormatter) Format(p problem) {
+	if o.suites == nil {
+		o.suites = map[string]*junitTestSuite{}
+	}
+	pkg := p.Package
+	if pkg == "" {
+		// Problems that don't belong to any package, such as
+		// problems in configuration files
+		pkg = "staticcheck"
+	}
+	suite, ok := o.suites[pkg]
+	if !ok {
+		suite = &junitTestSuite{Name: pkg}
+		o.suites[pkg] = suite
+	}
+
+	tc := junitTestCase{
+		ClassName: pkg,
+		Name:      fmt.Sprintf("%s: %s", p.Category, relativePositionString(p.Position)),
+	}
+	failure := &junitFailure{
+		Message: p.Message,
+		Type:    xmlSeverity(p),
+		Text:    fmt.Sprintf("%s: %s", relativePositionString(p.Position), messageWithRelated(p)),
+	}
+	suite.Tests++
+	switch {
+	case p.Severity == severityIgnored || p.Severity == severityBaselined:
+		tc.Skipped = &junitSkipped{Message: p.Message}
+		suite.Skipped++
+	case p.Category == "compile":
+		tc.Error = failure
+		suite.Errors++
+	default:
+		tc.Failure = failure
+		suite.Failures++
+	}
+	suite.TestCases = append(suite.TestCases, tc)
+}
+
+func (o *junitFormatter) Finish() {
+	names := make([]string, 0, len(o.suites))
+	for name := range o.suites {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	doc := struct {
+		XMLName  xml.Name         `xml:"testsuites"`
+		Name     string           `xml:"name,attr"`
+		Tests    int              `xml:"tests,attr"`
+		Failures int              `xml:"failures,attr"`
+		Errors   int              `xml:"errors,attr"`
+		Suites   []junitTestSuite `xml:"testsuite"`
+	}{Name: "staticcheck"}
+	for _, name := range names {
+		s := o.suites[name]
+		doc.Tests += s.Tests
+		doc.Failures += s.Failures
+		doc.Errors += s.Errors
+		doc.Suites = append(doc.Suites, *s)
+	}
+	fmt.Fprint(o.W, xml.Header)
+	enc := xml.NewEncoder(o.W)
+	enc.Indent("", "  ")
+	_ = enc.Encode(doc)
+	fmt.Fprintln(o.W)
+}
+
+// codeClimateFormatter emits a JSON array of Code Climate issues, as
+// consumed by GitLab's code quality reports.
+//
+// See https://github.com/codeclimate/platform/blob/master/spec/analyzers/SPEC.md
+type codeClimateFormatter struct {
+	W io.Writer
+
+	issues []codeClimateIssue
+	lh     lineHasher
+	// number of times each fingerprint has been seen
+	seen map[string]int
+}
+
+type codeClimateIssue struct {
+	Type        string              `json:"type"`
+	CheckName   string              `json:"check_name"`
+	Description string              `json:"description"`
+	Categories  []string            `json:"categories"`
+	Location    codeClimateLocation `json:"location"`
+	Severity    string              `json:"severity"`
+	Fingerprint string              `json:"fingerprint"`
+}
+
+type codeClimateLocation struct {
+	Path  string           `json:"path"`
+	Lines codeClimateLines `json:"lines"`
+}
+
+type codeClimateLines struct {
+	Begin int `json:"begin"`
+	End   int `json:"end"`
+}
+
+func codeClimateCategory(check string) string {
+	switch {
+	case strings.HasPrefix(check, "SA"):
+		return "Bug Risk"
+	case strings.HasPrefix(check, "ST"):
+		return "Style"
+	case strings.HasPrefix(check, "S"):
+		return "Complexity"
+	case check == "compile":
+		return "Bug Risk"
+	default:
+		return "Clarity"
+	}
+}
+
+func (o *codeClimateFormatter) Format(p problem) {
+	if p.Severity == severityIgnored || p.Severity == severityBaselined {
+		// Code Climate has no notion of suppressed issues
+		return
+	}
+	if o.seen == nil {
+		o.seen = map[string]int{}
+	}
+	path := filepath.ToSlash(shortPath(p.Position.Filename))
+
+	// Like baseline entries, fingerprints don't depend on line
+	// numbers, so that issues can be tracked across unrelated
+	// changes. Identical problems are numbered in order of
+	// appearance.
+	e := fingerprint(p, &o.lh)
+	e.File = path
+	b, _ := json.Marshal(e)
+	n := o.seen[string(b)]
+	o.seen[string(b)]++
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\n%d", b, n)))
+
+	severity := "major"
+	switch {
+	case p.Category == "compile":
+		severity = "critical"
+	case p.Severity == severityWarning:
+		severity = "minor"
+	case p.Severity == severityInfo || p.Severity == severityHint:
+		severity = "info"
+	}
+	end := p.Position.Line
+	if p.End.IsValid() && p.End.Filename == p.Position.Filename {
+		end = p.End.Line
+	}
+	o.issues = append(o.issues, codeClimateIssue{
+		Type:        "issue",
+		CheckName:   p.Category,
+		Description: messageWithRelated(p),
+		Categories:  []string{codeClimateCategory(p.Category)},
+		Location: codeClimateLocation{
+			Path:  path,
+			Lines: codeClimateLines{Begin: p.Position.Line, End: end},
+		},
+		Severity:    severity,
+		Fingerprint: hex.EncodeToString(sum[:16]),
+	})
+}
+
+func (o *codeClimateFormatter) Finish() {
+	issues := o.issues
+	if issues == nil {
+		// An empty report must still be an array
+		issues = []codeClimateIssue{}
+	}
+	enc := json.NewEncoder(o.W)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(issues)
+}
+
+// githubFormatter emits GitHub Actions workflow commands, which GitHub
+// shows as annotations of pull requests.
+//
+// See https://docs.github.com/en/actions/reference/workflow-commands-for-github-actions
+type githubFormatter struct {
+	W io.Writer
+}
+
+func (o githubFormatter) Format(p problem) {
+	cmd := "error"
+	switch p.Severity {
+	case severityWarning:
+		cmd = "warning"
+	case severityInfo, severityHint, severityIgnored, severityBaselined:
+		// Ignored and baselined problems are only formatted when
+		// -show-ignored was provided.
+		cmd = "notice"
+	}
+	o.command(cmd, p.Position, p.End, p.Category, p.Message)
+	for _, r := range p.Related {
+		o.command("notice", r.Position, r.End, p.Category, r.Message)
+	}
+}
+
+func (o githubFormatter) command(cmd string, pos, end token.Position, title, msg string) {
+	var props []string
+	if pos.Filename != "" {
+		props = append(props, "file="+githubEscapeProperty(filepath.ToSlash(shortPath(pos.Filename))))
+	}
+	if pos.IsValid() {
+		props = append(props, fmt.Sprintf("line=%d", pos.Line))
+		if pos.Column > 0 {
+			props = append(props, fmt.Sprintf("col=%d", pos.Column))
+		}
+		if end.IsValid() && end.Filename == pos.Filename {
+			props = append(props, fmt.Sprintf("endLine=%d", end.Line))
+			if end.Column > 0 {
+				props = append(props, fmt.Sprintf("endColumn=%d", end.Column))
+			}
+		}
+	}
+	props = append(props, "title="+githubEscapeProperty(title))
+	fmt.Fprintf(o.W, "::%s %s::%s\n", cmd, strings.Join(props, ","), githubEscapeData(msg))
+}
+
+var (
+	githubDataEscaper     = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	githubPropertyEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+)
+
+func githubEscapeData(s string) string     { return githubDataEscaper.Replace(s) }
+func githubEscapeProperty(s string) string { return githubPropertyEscaper.Replace(s) }
@@ -0,0 +1,259 @@
+package lintcmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"honnef.co/go/tools/lintcmd/runner"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+func TestSARIFFormatter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "staticcheck")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "pkg.go")
+	if err := ioutil.WriteFile(file, []byte("package pkg\n\nvar s = \"€\" + x\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := &bytes.Buffer{}
+	f := &sarifFormatter{
+		W:      buf,
+		Checks: []*analysis.Analyzer{{Name: "SA9999", Doc: "Some check\n\nMore text"}},
+	}
+	f.Format(problem{
+		Diagnostic: runner.Diagnostic{
+			// the column of x, after a three-byte rune
+			Position: token.Position{Filename: file, Line: 3, Column: 16},
+			End:      token.Position{Filename: file, Line: 3, Column: 17},
+			Category: "SA9999",
+			Message:  "something is wrong",
+			Related: []runner.RelatedInformation{{
+				Position: token.Position{Filename: file, Line: 1, Column: 1},
+				Message:  "related",
+			}},
+			SuggestedFixed: []runner.SuggestedFix{{
+				Message: "fix it",
+				TextEdits: []runner.TextEdit{{
+					Position: token.Position{Filename: file, Line: 3, Column: 16},
+					End:      token.Position{Filename: file, Line: 3, Column: 17},
+					NewText:  []byte("y"),
+				}},
+			}},
+		},
+		Severity: severityIgnored,
+	})
+	f.Finish()
+
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("couldn't decode SARIF output: %s", err)
+	}
+	if len(log.Runs) != 1 {
+		t.Fatalf("got %d runs, want 1", len(log.Runs))
+	}
+	run := log.Runs[0]
+	if len(run.Tool.Driver.Rules) != 1 || run.Tool.Driver.Rules[0].ShortDescription.Text != "Some check" {
+		t.Errorf("unexpected rules %#v", run.Tool.Driver.Rules)
+	} else if full := run.Tool.Driver.Rules[0].FullDescription; full == nil || full.Text != "More text" {
+		t.Errorf("got full description %#v, want %q", full, "More text")
+	}
+	if len(run.Results) != 1 {
+		t.Fatalf("got %d results, want 1", len(run.Results))
+	}
+	res := run.Results[0]
+	if res.RuleID != "SA9999" {
+		t.Errorf("got rule ID %q, want %q", res.RuleID, "SA9999")
+	}
+	if len(res.Suppressions) != 1 {
+		t.Errorf("ignored problem wasn't marked as suppressed")
+	}
+	region := res.Locations[0].PhysicalLocation.Region
+	if region.StartColumn != 14 || region.EndColumn != 15 {
+		t.Errorf("got columns %d-%d, want 14-15", region.StartColumn, region.EndColumn)
+	}
+	if len(res.RelatedLocations) != 1 || res.RelatedLocations[0].Message.Text != "related" {
+		t.Errorf("unexpected related locations %#v", res.RelatedLocations)
+	}
+	if len(res.Fixes) != 1 || res.Fixes[0].ArtifactChanges[0].Replacements[0].InsertedContent.Text != "y" {
+		t.Errorf("unexpected fixes %#v", res.Fixes)
+	}
+}
+
+func TestCheckstyleFormatter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	f := &checkstyleFormatter{W: buf}
+	f.Format(problem{
+		Diagnostic: runner.Diagnostic{
+			Position: token.Position{Filename: "/src/a.go", Line: 3, Column: 2},
+			Category: "SA4006",
+			Message:  `value of "x" is never used`,
+		},
+		Severity: severityWarning,
+	})
+	f.Format(problem{
+		Diagnostic: runner.Diagnostic{
+			Position: token.Position{Filename: "/src/b.go", Line: 1, Column: 1},
+			Category: "ST1000",
+			Message:  "at least one file in a package should have a package comment",
+		},
+	})
+	f.Finish()
+
+	var doc struct {
+		Files []checkstyleFile `xml:"file"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("couldn't decode Checkstyle output: %s", err)
+	}
+	want := []checkstyleFile{
+		{Name: "/src/a.go", Errors: []checkstyleError{{3, 2, "warning", `value of "x" is never used`, "staticcheck.SA4006"}}},
+		{Name: "/src/b.go", Errors: []checkstyleError{{1, 1, "error", "at least one file in a package should have a package comment", "staticcheck.ST1000"}}},
+	}
+	if !reflect.DeepEqual(doc.Files, want) {
+		t.Errorf("got %+v, want %+v", doc.Files, want)
+	}
+}
+
+func TestJUnitFormatter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	f := &junitFormatter{W: buf}
+	for _, p := range []problem{
+		{Diagnostic: runner.Diagnostic{Category: "SA4006", Message: "a"}, Package: "example.com/b"},
+		{Diagnostic: runner.Diagnostic{Category: "compile", Message: "b"}, Package: "example.com/a"},
+		{Diagnostic: runner.Diagnostic{Category: "SA4006", Message: "c"}, Package: "example.com/a"},
+		{Diagnostic: runner.Diagnostic{Category: "SA4006", Message: "d"}, Package: "example.com/a", Severity: severityIgnored},
+	} {
+		f.Format(p)
+	}
+	f.Finish()
+
+	var doc struct {
+		Tests    int              `xml:"tests,attr"`
+		Failures int              `xml:"failures,attr"`
+		Errors   int              `xml:"errors,attr"`
+		Suites   []junitTestSuite `xml:"testsuite"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("couldn't decode JUnit output: %s", err)
+	}
+	if doc.Tests != 4 || doc.Failures != 2 || doc.Errors != 1 {
+		t.Errorf("got %d tests, %d failures and %d errors, want 4, 2 and 1", doc.Tests, doc.Failures, doc.Errors)
+	}
+	if len(doc.Suites) != 2 || doc.Suites[0].Name != "example.com/a" || doc.Suites[1].Name != "example.com/b" {
+		t.Fatalf("unexpected test suites %+v", doc.Suites)
+	}
+	a := doc.Suites[0]
+	if a.Tests != 3 || a.Failures != 1 || a.Errors != 1 || a.Skipped != 1 {
+		t.Errorf("unexpected counts in %+v", a)
+	}
+	if a.TestCases[0].Error == nil || a.TestCases[1].Failure == nil || a.TestCases[2].Skipped == nil {
+		t.Errorf("unexpected test cases %+v", a.TestCases)
+	}
+}
+
+func TestCodeClimateFormatter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "staticcheck")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "pkg.go")
+	if err := ioutil.WriteFile(file, []byte("package pkg\n\nvar x = 1\nvar x = 1\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	format := func(lines ...int) []codeClimateIssue {
+		buf := &bytes.Buffer{}
+		f := &codeClimateFormatter{W: buf}
+		for _, line := range lines {
+			f.Format(problem{
+				Diagnostic: runner.Diagnostic{
+					Position: token.Position{Filename: file, Line: line, Column: 5},
+					Category: "U1000",
+					Message:  "var x is unused",
+				},
+				Severity: severityWarning,
+			})
+		}
+		f.Finish()
+		var issues []codeClimateIssue
+		if err := json.Unmarshal(buf.Bytes(), &issues); err != nil {
+			t.Fatalf("couldn't decode Code Climate output: %s", err)
+		}
+		return issues
+	}
+
+	issues := format(3, 4)
+	if len(issues) != 2 {
+		t.Fatalf("got %d issues, want 2", len(issues))
+	}
+	if issues[0].Fingerprint == issues[1].Fingerprint {
+		t.Errorf("identical problems have the same fingerprint")
+	}
+	if issues[0].Severity != "minor" || issues[0].Location.Lines.Begin != 3 {
+		t.Errorf("unexpected issue %+v", issues[0])
+	}
+	// Fingerprints don't depend on line numbers
+	if moved := format(4); moved[0].Fingerprint != issues[0].Fingerprint {
+		t.Errorf("fingerprint changed when the problem moved")
+	}
+	if empty := format(); empty == nil {
+		t.Errorf("empty report isn't an array")
+	}
+}
+
+func TestGitHubFormatter(t *testing.T) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := &bytes.Buffer{}
+	f := githubFormatter{W: buf}
+	f.Format(problem{
+		Diagnostic: runner.Diagnostic{
+			Position: token.Position{Filename: filepath.Join(cwd, "a,b.go"), Line: 3, Column: 2},
+			End:      token.Position{Filename: filepath.Join(cwd, "a,b.go"), Line: 4, Column: 7},
+			Category: "SA4006",
+			Message:  "100% wrong\nreally",
+			Related: []runner.RelatedInformation{{
+				Position: token.Position{Filename: filepath.Join(cwd, "c.go"), Line: 1, Column: 1},
+				Message:  "see here",
+			}},
+		},
+		Severity: severityWarning,
+	})
+	f.Format(problem{
+		Diagnostic: runner.Diagnostic{
+			Category: "compile",
+			Message:  "no position",
+		},
+	})
+	f.Format(problem{
+		Diagnostic: runner.Diagnostic{
+			Position: token.Position{Filename: filepath.Join(cwd, "d.go"), Line: 5, Column: 1},
+			Category: "ST1003",
+			Message:  "hint",
+		},
+		Severity: severityHint,
+	})
+
+	want := "::warning file=a%2Cb.go,line=3,col=2,endLine=4,endColumn=7,title=SA4006::100%25 wrong%0Areally\n" +
+		"::notice file=c.go,line=1,col=1,title=SA4006::see here\n" +
+		"::error title=compile::no position\n" +
+		"::notice file=d.go,line=5,col=1,title=ST1003::hint\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
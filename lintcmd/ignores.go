@@ -0,0 +1,169 @@
+package lintcmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// directiveState describes whether an ignore directive is in effect.
+type directiveState string
+
+const (
+	stateActive    directiveState = "active"
+	stateExpired   directiveState = "expired"
+	stateMalformed directiveState = "malformed"
+)
+
+// An inactiveDirective is an ignore directive that doesn't ignore
+// anything because it has expired or is malformed.
+type inactiveDirective struct {
+	Directive ignoreDirective
+	State     directiveState
+}
+
+// directiveUsage records how an ignore directive was used by all the
+// packages and configurations that its file is part of.
+type directiveUsage struct {
+	Directive ignoreDirective
+	// The zero value means that the directive is active
+	State   directiveState
+	Matched bool
+	// The problems suppressed by the directive. Problems are
+	// deduplicated the same way as when reporting them.
+	suppressed map[problemKey]struct{}
+}
+
+type problemKey struct {
+	Position token.Position
+	Category string
+	Message  string
+}
+
+func (u *directiveUsage) suppress(p problem) {
+	u.Matched = true
+	if u.suppressed == nil {
+		u.suppressed = map[problemKey]struct{}{}
+	}
+	u.suppressed[problemKey{p.Position, p.Category, p.Message}] = struct{}{}
+}
+
+// unknown reports whether it is unknown if the directive matched
+// anything. This is the case for directives that only ignore U1000,
+// which the unused check handles on its own.
+func (u *directiveUsage) unknown() bool {
+	if u.state() != stateActive {
+		return false
+	}
+	for _, c := range u.Directive.Checks {
+		if c != "U1000" {
+			return false
+		}
+	}
+	return !u.Matched
+}
+
+func (u *directiveUsage) state() directiveState {
+	if u.State == "" {
+		return stateActive
+	}
+	return u.State
+}
+
+// Suppressed returns the number of problems suppressed by the
+// directive.
+func (u *directiveUsage) Suppressed() int {
+	return len(u.suppressed)
+}
+
+// listIgnores prints the ignore directives recorded in usage, sorted
+// by position, in the given format, which is either "text" or
+// "json". Like the JSON formatter, the JSON format is a stream of
+// objects, one per directive.
+func listIgnores(w io.Writer, usage map[token.Position]*directiveUsage, format string) error {
+	us := make([]*directiveUsage, 0, len(usage))
+	for _, u := range usage {
+		us = append(us, u)
+	}
+	sort.Slice(us, func(i, j int) bool {
+		pi := us[i].Directive.Pos
+		pj := us[j].Directive.Pos
+		if pi.Filename != pj.Filename {
+			return pi.Filename < pj.Filename
+		}
+		if pi.Line != pj.Line {
+			return pi.Line < pj.Line
+		}
+		return pi.Column < pj.Column
+	})
+
+	switch format {
+	case "text":
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "POSITION\tDIRECTIVE\tCHECKS\tUNTIL\tSTATE\tMATCHED\tSUPPRESSED\tREASON")
+		for _, u := range us {
+			d := u.Directive
+			or := func(s string) string {
+				if s == "" {
+					return "-"
+				}
+				return s
+			}
+			matched := "no"
+			if u.Matched {
+				matched = "yes"
+			} else if u.unknown() {
+				matched = "unknown"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%d\t%s\n",
+				relativePositionString(d.Pos), d.Command, or(strings.Join(d.Checks, ",")), or(d.Until), u.state(), matched, u.Suppressed(), d.Reason)
+		}
+		return tw.Flush()
+	case "json":
+		type location struct {
+			File   string `json:"file"`
+			Line   int    `json:"line"`
+			Column int    `json:"column"`
+		}
+		enc := json.NewEncoder(w)
+		for _, u := range us {
+			d := u.Directive
+			jd := struct {
+				Location   location `json:"location"`
+				Directive  string   `json:"directive"`
+				Checks     []string `json:"checks"`
+				Reason     string   `json:"reason"`
+				Until      string   `json:"until,omitempty"`
+				State      string   `json:"state"`
+				Matched    *bool    `json:"matched"`
+				Suppressed int      `json:"suppressed"`
+			}{
+				Location: location{
+					File:   d.Pos.Filename,
+					Line:   d.Pos.Line,
+					Column: d.Pos.Column,
+				},
+				Directive:  d.Command,
+				Checks:     d.Checks,
+				Reason:     d.Reason,
+				Until:      d.Until,
+				State:      string(u.state()),
+				Suppressed: u.Suppressed(),
+			}
+			if !u.unknown() {
+				// Matched is null if it isn't known
+				jd.Matched = &u.Matched
+			}
+			if err := enc.Encode(jd); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported output format %q for -list-ignores", format)
+	}
+}
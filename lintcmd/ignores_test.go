@@ -0,0 +1,72 @@
+package lintcmd
+
+import (
+	"bytes"
+	"go/token"
+	"strings"
+	"testing"
+
+	"honnef.co/go/tools/lintcmd/runner"
+)
+
+func TestListIgnores(t *testing.T) {
+	pos := func(line int) token.Position {
+		return token.Position{Filename: "/a.go", Line: line, Column: 1}
+	}
+	prob := func(line int) problem {
+		return problem{Diagnostic: runner.Diagnostic{Position: pos(line), Category: "SA4000", Message: "bad"}}
+	}
+	usage := map[token.Position]*directiveUsage{}
+	add := func(id ignoreDirective, state directiveState, ps ...problem) {
+		u := &directiveUsage{Directive: id, State: state}
+		for _, p := range ps {
+			u.suppress(p)
+		}
+		usage[id.Pos] = u
+	}
+	add(ignoreDirective{Pos: pos(10), Command: "ignore", Checks: []string{"SA4000"}, Reason: "no match"}, "")
+	// The same problem suppressed twice, e.g. because the file is part
+	// of a package and its test variant, only counts once.
+	add(ignoreDirective{Pos: pos(1), Command: "file-ignore", Checks: []string{"SA4000"}, Reason: "generated", Until: "2027-01-01"}, "", prob(3), prob(5), prob(3))
+	add(ignoreDirective{Pos: pos(20), Command: "ignore", Checks: []string{"U1000"}, Reason: "used by reflection"}, "")
+	add(ignoreDirective{Pos: pos(30), Command: "ignore", Checks: []string{"SA4000"}, Reason: "old", Until: "2020-01-01"}, stateExpired)
+	add(ignoreDirective{Pos: pos(40), Command: "ignore", Checks: []string{"SA4000"}}, stateMalformed)
+
+	buf := &bytes.Buffer{}
+	if err := listIgnores(buf, usage, "json"); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"location":{"file":"/a.go","line":1,"column":1},"directive":"file-ignore","checks":["SA4000"],"reason":"generated","until":"2027-01-01","state":"active","matched":true,"suppressed":2}
+{"location":{"file":"/a.go","line":10,"column":1},"directive":"ignore","checks":["SA4000"],"reason":"no match","state":"active","matched":false,"suppressed":0}
+{"location":{"file":"/a.go","line":20,"column":1},"directive":"ignore","checks":["U1000"],"reason":"used by reflection","state":"active","matched":null,"suppressed":0}
+{"location":{"file":"/a.go","line":30,"column":1},"directive":"ignore","checks":["SA4000"],"reason":"old","until":"2020-01-01","state":"expired","matched":false,"suppressed":0}
+{"location":{"file":"/a.go","line":40,"column":1},"directive":"ignore","checks":["SA4000"],"reason":"","state":"malformed","matched":false,"suppressed":0}
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	buf.Reset()
+	if err := listIgnores(buf, usage, "text"); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 6 || !strings.HasPrefix(lines[0], "POSITION") {
+		t.Fatalf("unexpected output:\n%s", buf)
+	}
+	for i, want := range [][2]string{
+		{"active", "yes"},
+		{"active", "no"},
+		{"active", "unknown"},
+		{"expired", "no"},
+		{"malformed", "no"},
+	} {
+		if fields := strings.Fields(lines[i+1]); fields[4] != want[0] || fields[5] != want[1] {
+			t.Errorf("line %d: got state %q and matched %q, want %q and %q", i+1, fields[4], fields[5], want[0], want[1])
+		}
+	}
+
+	if err := listIgnores(buf, usage, "sarif"); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+}
@@ -0,0 +1,335 @@
+package lintcmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"honnef.co/go/tools/config"
+	"honnef.co/go/tools/go/loader"
+	"honnef.co/go/tools/lintcmd/runner"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// This file implements incremental linting, which the language server
+// uses to keep its results up to date as files are saved, and which
+// -watch builds on.
+//
+// The package graph is loaded once, and the files of its packages are
+// polled for changes, which is portable and cheap enough for the
+// number of files in a typical module. When files change, only the
+// affected packages and their dependents are reanalyzed; the graph is
+// updated by loader.Refresh instead of running go list again.
+// Changes that Refresh can't handle, such as added imports, new files
+// or modified configuration files, cause the graph to be loaded again.
+
+// A fileStamp identifies a version of a file. Missing files have the
+// zero stamp.
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+}
+
+func stampFile(name string) fileStamp {
+	fi, err := os.Stat(name)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{fi.Size(), fi.ModTime()}
+}
+
+// A trackedDir is a directory containing the files of tracked
+// packages. Files being added to or removed from it require loading
+// the package graph again.
+type trackedDir struct {
+	stamp fileStamp
+	// Go and configuration files in the directory
+	names string
+}
+
+// dirNames returns the sorted names of Go and configuration files in
+// dir.
+func dirNames(dir string) string {
+	f, err := os.Open(dir)
+	if err != nil {
+		return ""
+	}
+	names, _ := f.Readdirnames(-1)
+	f.Close()
+	var out []string
+	for _, name := range names {
+		if strings.HasSuffix(name, ".go") || name == config.ConfigName {
+			out = append(out, name)
+		}
+	}
+	sort.Strings(out)
+	return strings.Join(out, "\n")
+}
+
+// A lintedGraph is the package graph of one build configuration,
+// together with the latest results of its packages.
+type lintedGraph struct {
+	graph   []*loader.PackageSpec
+	results map[*loader.PackageSpec]runner.Result
+	// the packages that tracked Go files belong to
+	files map[string][]*loader.PackageSpec
+}
+
+// An incrementalLinter lints the same packages over and over again,
+// only reanalyzing those affected by changes since the previous run.
+type incrementalLinter struct {
+	l        *linter
+	cfgs     []*packages.Config
+	patterns []string
+	// nil if the graphs haven't been loaded yet, or if the last
+	// attempt at updating them failed
+	graphs []*lintedGraph
+
+	files map[string]fileStamp
+	dirs  map[string]*trackedDir
+	// files that the package graph depends on, such as go.mod and
+	// configuration files
+	graphFiles map[string]fileStamp
+}
+
+// load loads the package graphs and analyzes all packages.
+func (il *incrementalLinter) load() error {
+	graphs := make([]*lintedGraph, len(il.cfgs))
+	for i, cfg := range il.cfgs {
+		graph, err := il.l.Runner.Graph(cfg, il.patterns)
+		if err != nil {
+			return err
+		}
+		res, err := il.l.Runner.RunGraph(graph, il.l.Checkers)
+		if err != nil {
+			return err
+		}
+		g := &lintedGraph{
+			graph:   graph,
+			results: map[*loader.PackageSpec]runner.Result{},
+			files:   map[string][]*loader.PackageSpec{},
+		}
+		for _, r := range res {
+			g.results[r.Package] = r
+		}
+		graphs[i] = g
+	}
+	il.graphs = graphs
+	il.track()
+	return nil
+}
+
+// track determines the files to poll and records their current state.
+// It tracks the packages of the modules that initial packages belong
+// to; dependencies in the module cache or in GOROOT don't change.
+func (il *incrementalLinter) track() {
+	il.files = map[string]fileStamp{}
+	il.dirs = map[string]*trackedDir{}
+	il.graphFiles = map[string]fileStamp{}
+
+	var roots []string
+	for _, g := range il.graphs {
+		for _, spec := range g.graph {
+			dir := config.Dir(spec.GoFiles)
+			if dir == "" {
+				continue
+			}
+			root := moduleRoot(dir)
+			if root == "" {
+				// Without a module, only track initial packages
+				root = dir
+			} else if _, ok := il.dirs[root]; !ok {
+				// The module's root directory is a likely place for
+				// configuration files to appear in.
+				il.dirs[root] = &trackedDir{stampFile(root), dirNames(root)}
+				il.graphFiles[filepath.Join(root, "go.mod")] = fileStamp{}
+			}
+			roots = append(roots, root)
+		}
+	}
+	inRoots := func(dir string) bool {
+		for _, root := range roots {
+			if dir == root || strings.HasPrefix(dir, root+string(filepath.Separator)) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, g := range il.graphs {
+		seen := map[*loader.PackageSpec]bool{}
+		var visit func(spec *loader.PackageSpec)
+		visit = func(spec *loader.PackageSpec) {
+			if seen[spec] {
+				return
+			}
+			seen[spec] = true
+			for _, imp := range spec.Imports {
+				visit(imp)
+			}
+			dir := config.Dir(spec.GoFiles)
+			if dir == "" || !inRoots(dir) {
+				return
+			}
+			if _, ok := il.dirs[dir]; !ok {
+				il.dirs[dir] = &trackedDir{stampFile(dir), dirNames(dir)}
+				if files, err := config.Files(dir); err == nil {
+					for _, f := range files {
+						il.graphFiles[f] = fileStamp{}
+					}
+				}
+			}
+			for _, f := range spec.GoFiles {
+				g.files[f] = append(g.files[f], spec)
+				il.files[f] = fileStamp{}
+			}
+		}
+		for _, spec := range g.graph {
+			visit(spec)
+		}
+	}
+
+	for f := range il.files {
+		il.files[f] = stampFile(f)
+	}
+	for f := range il.graphFiles {
+		il.graphFiles[f] = stampFile(f)
+	}
+}
+
+// moduleRoot returns the directory of the go.mod file of the module
+// that dir belongs to, or the empty string if there is none.
+func moduleRoot(dir string) string {
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// poll checks tracked files for changes. It returns the Go files that
+// have changed, and whether the package graph has to be loaded again.
+func (il *incrementalLinter) poll() (changed []string, reload bool) {
+	for f, old := range il.graphFiles {
+		if stamp := stampFile(f); stamp != old {
+			il.graphFiles[f] = stamp
+			reload = true
+		}
+	}
+	for dir, td := range il.dirs {
+		stamp := stampFile(dir)
+		if stamp == td.stamp {
+			continue
+		}
+		td.stamp = stamp
+		// Saving files atomically modifies the directory without
+		// changing the set of files.
+		if names := dirNames(dir); names != td.names {
+			td.names = names
+			reload = true
+		}
+	}
+	for f, old := range il.files {
+		if stamp := stampFile(f); stamp != old {
+			il.files[f] = stamp
+			changed = append(changed, f)
+		}
+	}
+	return changed, reload
+}
+
+// update reanalyzes the packages affected by changes to files.
+func (il *incrementalLinter) update(files []string) error {
+	for _, g := range il.graphs {
+		var changed []*loader.PackageSpec
+		for _, f := range files {
+			changed = append(changed, g.files[f]...)
+		}
+		if len(changed) == 0 {
+			continue
+		}
+		affected, err := loader.Refresh(g.graph, changed)
+		if err != nil {
+			return err
+		}
+		isAffected := map[*loader.PackageSpec]bool{}
+		for _, spec := range affected {
+			isAffected[spec] = true
+		}
+		var initial []*loader.PackageSpec
+		for _, spec := range g.graph {
+			if isAffected[spec] {
+				initial = append(initial, spec)
+			}
+		}
+		res, err := il.l.Runner.RunGraph(initial, il.l.Checkers)
+		if err != nil {
+			return err
+		}
+		// Unaffected dependencies of the affected packages weren't
+		// analyzed as initial packages this time; keep their
+		// previous results.
+		for _, r := range res {
+			if isAffected[r.Package] {
+				g.results[r.Package] = r
+			}
+		}
+	}
+	return nil
+}
+
+// refresh brings the results up to date with the changes reported by
+// poll. It loads the package graphs again if reload is set, if the
+// changes can't be applied to the graphs, or if the graphs haven't
+// been loaded successfully yet.
+func (il *incrementalLinter) refresh(changed []string, reload bool) error {
+	var err error
+	if reload || il.graphs == nil {
+		err = il.load()
+	} else if len(changed) > 0 {
+		err = il.update(changed)
+		if err == loader.ErrGraphChanged {
+			err = il.load()
+		}
+	}
+	if err != nil {
+		// The graphs are in an unknown state; load them again next
+		// time.
+		il.graphs = nil
+	}
+	return err
+}
+
+func (il *incrementalLinter) results() []runner.Result {
+	var out []runner.Result
+	for _, g := range il.graphs {
+		for _, r := range g.results {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// lint returns the problems of the packages matched by patterns. It
+// only reanalyzes the packages affected by changes since the previous
+// call, unless patterns differ from those of the previous call or the
+// changes require loading the package graph again.
+func (il *incrementalLinter) lint(patterns []string) ([]problem, []string, error) {
+	if !reflect.DeepEqual(patterns, il.patterns) {
+		il.patterns = patterns
+		il.graphs = nil
+	}
+	if err := il.refresh(il.poll()); err != nil {
+		return nil, nil, err
+	}
+	return il.l.problems(il.results())
+}
@@ -0,0 +1,217 @@
+package lintcmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"honnef.co/go/tools/config"
+	"honnef.co/go/tools/go/loader"
+	"honnef.co/go/tools/lintcmd/runner"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/packages"
+)
+
+func TestIncrementalPoll(t *testing.T) {
+	dir, err := ioutil.TempDir("", "staticcheck")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "pkg.go")
+	if err := ioutil.WriteFile(file, []byte("package pkg\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	il := &incrementalLinter{
+		files:      map[string]fileStamp{file: stampFile(file)},
+		dirs:       map[string]*trackedDir{dir: {stampFile(dir), dirNames(dir)}},
+		graphFiles: map[string]fileStamp{},
+	}
+	if changed, reload := il.poll(); len(changed) != 0 || reload {
+		t.Fatalf("got changes %v and reload %t without changing anything", changed, reload)
+	}
+
+	// Make sure that the modification time changes, even on file
+	// systems with coarse timestamps.
+	mtime := time.Now().Add(time.Hour)
+	if err := ioutil.WriteFile(file, []byte("package pkg\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(file, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+	if changed, reload := il.poll(); len(changed) != 1 || changed[0] != file || reload {
+		t.Errorf("got changes %v and reload %t, want %s and false", changed, reload, file)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "new.go"), []byte("package pkg\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(dir, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+	if _, reload := il.poll(); !reload {
+		t.Error("adding a file didn't require reloading the graph")
+	}
+}
+
+// newTestIncrementalLinter returns an incremental linter for a GOPATH in dir with the
+// packages a, b and c, where b depends on a and c is unrelated, and a
+// function that writes the file of a package.
+func newTestIncrementalLinter(t *testing.T, dir string) (*incrementalLinter, func(pkg, src string) string) {
+	write := func(pkg, src string) string {
+		name := filepath.Join(dir, "src", pkg, pkg+".go")
+		if err := os.MkdirAll(filepath.Dir(name), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(name, []byte(src), 0666); err != nil {
+			t.Fatal(err)
+		}
+		return name
+	}
+	write("a", "package a\n\nfunc F() int { return 1 }\n")
+	write("b", "package b\n\nimport \"a\"\n\nfunc G() int { return a.F() }\n")
+	write("c", "package c\n\nfunc H() int { return 1 }\n")
+
+	l, err := newLinter(config.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Checkers = []*analysis.Analyzer{{
+		Name: "XX9999",
+		Doc:  "does nothing",
+		Run:  func(*analysis.Pass) (interface{}, error) { return nil, nil },
+	}}
+	cfg := &packages.Config{
+		Env: append(os.Environ(), "GOPATH="+dir, "GO111MODULE=off"),
+	}
+	return &incrementalLinter{l: l, cfgs: []*packages.Config{cfg}}, write
+}
+
+// analyzedPackages returns the IDs of the packages that profile
+// records as analyzed.
+func analyzedPackages(profile *runner.Profile) map[string]bool {
+	analyzed := map[string]bool{}
+	for _, p := range profile.Packages() {
+		if p.CacheMisses > 0 {
+			analyzed[p.ID] = true
+		}
+	}
+	return analyzed
+}
+
+func TestIncrementalUpdate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "staticcheck")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	il, write := newTestIncrementalLinter(t, dir)
+	l := il.l
+	il.patterns = []string{"a", "b", "c"}
+	if err := il.load(); err != nil {
+		t.Fatal(err)
+	}
+	g := il.graphs[0]
+	specs := map[string]*loader.PackageSpec{}
+	for _, spec := range g.graph {
+		specs[spec.ID] = spec
+	}
+	oldC, ok := g.results[specs["c"]]
+	if !ok {
+		t.Fatal("no result for c")
+	}
+
+	// The unique comment makes sure that the edited package isn't in
+	// the cache from earlier runs of the test.
+	afile := write("a", fmt.Sprintf("package a\n\n// %d\nfunc F() int { return 2 }\n", time.Now().UnixNano()))
+	l.Runner.Profile = &runner.Profile{}
+	if err := il.update([]string{afile}); err != nil {
+		t.Fatal(err)
+	}
+	if analyzed := analyzedPackages(l.Runner.Profile); !analyzed["a"] || !analyzed["b"] || analyzed["c"] {
+		t.Errorf("reanalyzed %v, want a and b", analyzed)
+	}
+	for _, id := range []string{"a", "b"} {
+		if r := g.results[specs[id]]; r.Failed {
+			t.Errorf("%s failed after the update: %v", id, r.Errors)
+		}
+	}
+	if !reflect.DeepEqual(g.results[specs["c"]], oldC) {
+		t.Error("result of unaffected package c changed")
+	}
+
+	// Syntax errors, even in the imports, don't change the graph.
+	bfile := write("b", "package b\n\nimport \"a\"\nimport (\n")
+	if err := il.update([]string{bfile}); err != nil {
+		t.Errorf("syntax error returned %v, want nil", err)
+	}
+
+	write("a", "package a\n\nimport \"c\"\n\nfunc F() int { return c.H() }\n")
+	if err := il.update([]string{afile}); err != loader.ErrGraphChanged {
+		t.Errorf("adding an import returned %v, want %v", err, loader.ErrGraphChanged)
+	}
+}
+
+func TestIncrementalLint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "staticcheck")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	il, write := newTestIncrementalLinter(t, dir)
+	l := il.l
+	patterns := []string{"a", "b", "c"}
+
+	lint := func() map[string]bool {
+		t.Helper()
+		l.Runner.Profile = &runner.Profile{}
+		if _, _, err := il.lint(patterns); err != nil {
+			t.Fatal(err)
+		}
+		return analyzedPackages(l.Runner.Profile)
+	}
+	lint()
+	if analyzed := lint(); len(analyzed) != 0 {
+		t.Errorf("reanalyzed %v without changes", analyzed)
+	}
+
+	graph := il.graphs[0].graph
+
+	// New files require loading the graph again.
+	name := filepath.Join(dir, "src", "c", "c2.go")
+	src := fmt.Sprintf("package c\n\n// %d\nfunc I() int { return 1 }\n", time.Now().UnixNano())
+	if err := ioutil.WriteFile(name, []byte(src), 0666); err != nil {
+		t.Fatal(err)
+	}
+	// b isn't checked because it isn't cached if loading a from
+	// export data fails, which happens with newer versions of Go.
+	if analyzed := lint(); !analyzed["c"] || analyzed["a"] {
+		t.Errorf("reanalyzed %v, want c", analyzed)
+	}
+	if reflect.DeepEqual(il.graphs[0].graph, graph) {
+		t.Error("package graph wasn't loaded again after adding a file")
+	}
+
+	write("a", fmt.Sprintf("package a\n\n// %d\nfunc F() int { return 2 }\n", time.Now().UnixNano()))
+	if analyzed := lint(); !analyzed["a"] || !analyzed["b"] || analyzed["c"] {
+		t.Errorf("reanalyzed %v, want a and b", analyzed)
+	}
+	if !reflect.DeepEqual(il.patterns, patterns) || len(il.graphs) != 1 {
+		t.Errorf("unexpected state after linting")
+	}
+
+	// Changes that Refresh can't apply load the graph again.
+	graph = il.graphs[0].graph
+	write("a", "package a\n\nimport \"c\"\n\nfunc F() int { return c.H() }\n")
+	lint()
+	if reflect.DeepEqual(il.graphs[0].graph, graph) {
+		t.Error("package graph wasn't loaded again after adding an import")
+	}
+}
@@ -31,7 +31,7 @@ func lintPackage(t *testing.T, name string) []problem {
 	cfg := &packages.Config{
 		Env: append(os.Environ(), "GOPATH="+testdata(), "GO111MODULE=off"),
 	}
-	ps, _, err := l.Lint(cfg, []string{name})
+	ps, _, err := l.Lint([]*packages.Config{cfg}, []string{name})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -0,0 +1,106 @@
+package lintcmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"unicode"
+
+	"honnef.co/go/tools/analysis/lint"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// checkInfo describes a check, as printed by -list-checks.
+type checkInfo struct {
+	Name     string `json:"name"`
+	Category string `json:"category"`
+	Title    string `json:"title"`
+	Since    string `json:"since,omitempty"`
+	Default  bool   `json:"default"`
+	// Whether the check is enabled by the effective configuration
+	Enabled bool `json:"enabled"`
+}
+
+// checkCategory returns the category of a check, which is the prefix
+// of its name that is matched by globs such as "SA*".
+func checkCategory(name string) string {
+	if idx := strings.IndexFunc(name, unicode.IsNumber); idx >= 0 {
+		return name[:idx]
+	}
+	return name
+}
+
+// docTitle returns the title and text of a check's documentation.
+// Not all analyzers have structured documentation; for those, the
+// first line of their Doc is the title and the rest is the text.
+func docTitle(c *analysis.Analyzer) (title, text string) {
+	if doc := lint.DocumentationOf(c); doc != nil {
+		return doc.Title, doc.Text
+	}
+	title = c.Doc
+	if idx := strings.IndexByte(title, '\n'); idx >= 0 {
+		title, text = title[:idx], strings.TrimSpace(title[idx+1:])
+	}
+	return title, text
+}
+
+func describeChecks(cs []*analysis.Analyzer, enabled map[string]bool) []checkInfo {
+	out := make([]checkInfo, 0, len(cs))
+	for _, c := range cs {
+		info := checkInfo{
+			Name:     c.Name,
+			Category: checkCategory(c.Name),
+			Default:  true,
+			Enabled:  enabled[c.Name],
+		}
+		info.Title, _ = docTitle(c)
+		if doc := lint.DocumentationOf(c); doc != nil {
+			info.Since = doc.Since
+			info.Default = !doc.NonDefault
+		}
+		out = append(out, info)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// listChecks prints checks in the given format, which is either
+// "text" or "json". Like the JSON formatter, the JSON format is a
+// stream of objects, one per check.
+func listChecks(w io.Writer, checks []checkInfo, format string) error {
+	switch format {
+	case "text":
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "CHECK\tCATEGORY\tSINCE\tDEFAULT\tENABLED\tTITLE")
+		for _, c := range checks {
+			since := c.Since
+			if since == "" {
+				since = "unreleased"
+			}
+			yesNo := func(b bool) string {
+				if b {
+					return "yes"
+				}
+				return "no"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", c.Name, c.Category, since, yesNo(c.Default), yesNo(c.Enabled), c.Title)
+		}
+		return tw.Flush()
+	case "json":
+		enc := json.NewEncoder(w)
+		for _, c := range checks {
+			if err := enc.Encode(c); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported output format %q for -list-checks", format)
+	}
+}
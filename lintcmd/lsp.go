@@ -0,0 +1,624 @@
+package lintcmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/token"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf16"
+
+	"honnef.co/go/tools/lintcmd/version"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// This file implements a minimal language server, which publishes
+// staticcheck's problems as diagnostics. It only implements the parts
+// of the Language Server Protocol that are needed for that and leaves
+// everything else to other language servers, such as gopls.
+//
+// Packages are analyzed when one of their files is opened or saved.
+// Every analysis lints all packages that have been opened so far,
+// using an incrementalLinter. The package graph is only loaded again
+// when a new directory is opened or the graph changes; otherwise, only
+// the packages affected by the changed files and their dependents are
+// reanalyzed.
+//
+// See https://microsoft.github.io/language-server-protocol/specifications/specification-3-15/
+
+// JSON-RPC error codes
+const (
+	lspParseError     = -32700
+	lspMethodNotFound = -32601
+	lspInvalidParams  = -32602
+)
+
+type lspMessage struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+}
+
+type lspResponse struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id"`
+	Result  interface{}      `json:"result"`
+}
+
+type lspErrorResponse struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id"`
+	Error   lspError         `json:"error"`
+}
+
+type lspError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type lspNotification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+type lspPosition struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+func (p lspPosition) less(o lspPosition) bool {
+	return p.Line < o.Line || (p.Line == o.Line && p.Character < o.Character)
+}
+
+type lspRange struct {
+	Start lspPosition `json:"start"`
+	End   lspPosition `json:"end"`
+}
+
+// overlaps reports whether r and o overlap. Empty ranges overlap
+// ranges that contain them.
+func (r lspRange) overlaps(o lspRange) bool {
+	return !r.End.less(o.Start) && !o.End.less(r.Start)
+}
+
+type lspLocation struct {
+	URI   string   `json:"uri"`
+	Range lspRange `json:"range"`
+}
+
+type lspTextDocumentIdentifier struct {
+	URI string `json:"uri"`
+}
+
+type lspInitializeResult struct {
+	Capabilities lspServerCapabilities `json:"capabilities"`
+	ServerInfo   lspServerInfo         `json:"serverInfo"`
+}
+
+type lspServerCapabilities struct {
+	TextDocumentSync   lspTextDocumentSyncOptions `json:"textDocumentSync"`
+	CodeActionProvider bool                       `json:"codeActionProvider"`
+	HoverProvider      bool                       `json:"hoverProvider"`
+}
+
+type lspTextDocumentSyncOptions struct {
+	OpenClose bool `json:"openClose"`
+	// We only look at files on disk and don't need their contents
+	Change int  `json:"change"`
+	Save   bool `json:"save"`
+}
+
+type lspServerInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type lspDocumentParams struct {
+	TextDocument lspTextDocumentIdentifier `json:"textDocument"`
+}
+
+type lspCodeActionParams struct {
+	TextDocument lspTextDocumentIdentifier `json:"textDocument"`
+	Range        lspRange                  `json:"range"`
+}
+
+type lspHoverParams struct {
+	TextDocument lspTextDocumentIdentifier `json:"textDocument"`
+	Position     lspPosition               `json:"position"`
+}
+
+type lspPublishDiagnosticsParams struct {
+	URI         string          `json:"uri"`
+	Diagnostics []lspDiagnostic `json:"diagnostics"`
+}
+
+type lspDiagnostic struct {
+	Range              lspRange                `json:"range"`
+	Severity           int                     `json:"severity"`
+	Code               string                  `json:"code"`
+	Source             string                  `json:"source"`
+	Message            string                  `json:"message"`
+	RelatedInformation []lspRelatedInformation `json:"relatedInformation,omitempty"`
+}
+
+type lspRelatedInformation struct {
+	Location lspLocation `json:"location"`
+	Message  string      `json:"message"`
+}
+
+type lspCodeAction struct {
+	Title       string           `json:"title"`
+	Kind        string           `json:"kind"`
+	Diagnostics []lspDiagnostic  `json:"diagnostics"`
+	Edit        lspWorkspaceEdit `json:"edit"`
+}
+
+type lspWorkspaceEdit struct {
+	Changes map[string][]lspTextEdit `json:"changes"`
+}
+
+type lspTextEdit struct {
+	Range   lspRange `json:"range"`
+	NewText string   `json:"newText"`
+}
+
+type lspHover struct {
+	Contents lspMarkupContent `json:"contents"`
+	Range    lspRange         `json:"range"`
+}
+
+type lspMarkupContent struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+// Diagnostic severities
+const (
+	lspSeverityError       = 1
+	lspSeverityWarning     = 2
+	lspSeverityInformation = 3
+	lspSeverityHint        = 4
+)
+
+// readLSPMessage reads a single message, which is preceded by a header
+// that specifies its length.
+func readLSPMessage(r *bufio.Reader) ([]byte, error) {
+	length := -1
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+		idx := strings.IndexByte(line, ':')
+		if idx == -1 {
+			return nil, fmt.Errorf("malformed header %q", line)
+		}
+		if strings.EqualFold(line[:idx], "Content-Length") {
+			length, err = strconv.Atoi(strings.TrimSpace(line[idx+1:]))
+			if err != nil {
+				return nil, fmt.Errorf("malformed header %q", line)
+			}
+		}
+	}
+	if length < 0 {
+		return nil, errors.New("missing Content-Length header")
+	}
+	b := make([]byte, length)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func writeLSPMessage(w io.Writer, msg interface{}) error {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "Content-Length: %d\r\n\r\n%s", len(b), b)
+	return err
+}
+
+// uriToPath converts a file URI to a file name.
+func uriToPath(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "file" {
+		return "", fmt.Errorf("unsupported URI %q", uri)
+	}
+	path := u.Path
+	if runtime.GOOS == "windows" && len(path) > 2 && path[0] == '/' && path[2] == ':' {
+		// /C:/foo
+		path = path[1:]
+	}
+	return filepath.FromSlash(path), nil
+}
+
+// lspEntry is a published diagnostic, together with the code actions
+// that fix it.
+type lspEntry struct {
+	diag    lspDiagnostic
+	actions []lspCodeAction
+}
+
+// An lspServer publishes problems as diagnostics.
+type lspServer struct {
+	// lint lints the packages in the given directories.
+	lint   func(dirs []string) ([]problem, []string, error)
+	checks []*analysis.Analyzer
+
+	out   io.Writer
+	outMu sync.Mutex
+	// Warnings and errors of the linter are logged here
+	log io.Writer
+
+	mu sync.Mutex
+	// Directories of packages that have been opened
+	dirs map[string]bool
+	// Published diagnostics, keyed by URI
+	entries map[string][]lspEntry
+	// Whether the client asked us to shut down
+	shutdown bool
+
+	// Requests to lint are coalesced, so that a burst of saves only
+	// causes a single analysis.
+	pending chan struct{}
+	done    chan struct{}
+}
+
+func newLSPServer(checks []*analysis.Analyzer, lint func(dirs []string) ([]problem, []string, error), out, log io.Writer) *lspServer {
+	return &lspServer{
+		lint:    lint,
+		checks:  checks,
+		out:     out,
+		log:     log,
+		dirs:    map[string]bool{},
+		entries: map[string][]lspEntry{},
+		pending: make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+}
+
+// serve handles messages until the client sends an exit notification
+// or closes the connection. It reports whether the client shut the
+// server down properly.
+func (s *lspServer) serve(in io.Reader) (bool, error) {
+	go s.linter()
+	defer close(s.done)
+
+	r := bufio.NewReader(in)
+	for {
+		b, err := readLSPMessage(r)
+		if err != nil {
+			if err == io.EOF {
+				return false, nil
+			}
+			return false, err
+		}
+		var msg lspMessage
+		if err := json.Unmarshal(b, &msg); err != nil {
+			s.replyError(nil, lspParseError, err.Error())
+			continue
+		}
+		if msg.Method == "exit" {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			return s.shutdown, nil
+		}
+		result, rerr := s.handle(msg)
+		if msg.ID == nil {
+			// Notifications don't get responses
+			continue
+		}
+		if rerr != nil {
+			s.replyError(msg.ID, rerr.Code, rerr.Message)
+		} else {
+			s.send(lspResponse{JSONRPC: "2.0", ID: msg.ID, Result: result})
+		}
+	}
+}
+
+func (s *lspServer) handle(msg lspMessage) (interface{}, *lspError) {
+	switch msg.Method {
+	case "initialize":
+		info := lspServerInfo{Name: "staticcheck"}
+		if version.Version != "devel" {
+			info.Version = version.Version
+		}
+		return lspInitializeResult{
+			Capabilities: lspServerCapabilities{
+				TextDocumentSync: lspTextDocumentSyncOptions{
+					OpenClose: true,
+					Save:      true,
+				},
+				CodeActionProvider: true,
+				HoverProvider:      true,
+			},
+			ServerInfo: info,
+		}, nil
+	case "initialized":
+		return nil, nil
+	case "shutdown":
+		s.mu.Lock()
+		s.shutdown = true
+		s.mu.Unlock()
+		return nil, nil
+	case "textDocument/didOpen", "textDocument/didSave":
+		var params lspDocumentParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, &lspError{lspInvalidParams, err.Error()}
+		}
+		path, err := uriToPath(params.TextDocument.URI)
+		if err != nil {
+			return nil, &lspError{lspInvalidParams, err.Error()}
+		}
+		if filepath.Ext(path) != ".go" {
+			return nil, nil
+		}
+		s.mu.Lock()
+		known := s.dirs[filepath.Dir(path)]
+		s.dirs[filepath.Dir(path)] = true
+		s.mu.Unlock()
+		if msg.Method == "textDocument/didSave" || !known {
+			s.requestLint()
+		}
+		return nil, nil
+	case "textDocument/codeAction":
+		var params lspCodeActionParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, &lspError{lspInvalidParams, err.Error()}
+		}
+		actions := []lspCodeAction{}
+		s.mu.Lock()
+		for _, e := range s.entries[params.TextDocument.URI] {
+			if e.diag.Range.overlaps(params.Range) {
+				actions = append(actions, e.actions...)
+			}
+		}
+		s.mu.Unlock()
+		return actions, nil
+	case "textDocument/hover":
+		var params lspHoverParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, &lspError{lspInvalidParams, err.Error()}
+		}
+		return s.hover(params), nil
+	default:
+		if msg.ID != nil && !strings.HasPrefix(msg.Method, "$/") {
+			return nil, &lspError{lspMethodNotFound, fmt.Sprintf("method %q not supported", msg.Method)}
+		}
+		return nil, nil
+	}
+}
+
+// hover returns the documentation of the checks that flagged the
+// position in params, or nil if there are none.
+func (s *lspServer) hover(params lspHoverParams) interface{} {
+	at := lspRange{Start: params.Position, End: params.Position}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var docs []string
+	var rng lspRange
+	seen := map[string]bool{}
+	for _, e := range s.entries[params.TextDocument.URI] {
+		if !e.diag.Range.overlaps(at) || seen[e.diag.Code] {
+			continue
+		}
+		seen[e.diag.Code] = true
+		c, ok := findCheck(s.checks, e.diag.Code)
+		if !ok || c.Doc == "" {
+			continue
+		}
+		if len(docs) == 0 {
+			rng = e.diag.Range
+		}
+		docs = append(docs, fmt.Sprintf("**%s**: %s", c.Name, c.Doc))
+	}
+	if len(docs) == 0 {
+		return nil
+	}
+	return lspHover{
+		Contents: lspMarkupContent{Kind: "markdown", Value: strings.Join(docs, "\n\n---\n\n")},
+		Range:    rng,
+	}
+}
+
+func (s *lspServer) requestLint() {
+	select {
+	case s.pending <- struct{}{}:
+	default:
+		// An analysis is already pending
+	}
+}
+
+func (s *lspServer) linter() {
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-s.pending:
+		}
+		s.mu.Lock()
+		dirs := make([]string, 0, len(s.dirs))
+		for dir := range s.dirs {
+			dirs = append(dirs, dir)
+		}
+		s.mu.Unlock()
+		sort.Strings(dirs)
+
+		ps, warnings, err := s.lint(dirs)
+		for _, w := range warnings {
+			fmt.Fprintln(s.log, "warning:", w)
+		}
+		if err != nil {
+			fmt.Fprintln(s.log, err)
+			continue
+		}
+		s.publish(ps)
+	}
+}
+
+// publish replaces all published diagnostics with ps.
+func (s *lspServer) publish(ps []problem) {
+	c := &lspConverter{}
+	entries := map[string][]lspEntry{}
+	for _, p := range ps {
+		if p.Category == "compile" || !p.Position.IsValid() {
+			// Other language servers already report compile errors
+			continue
+		}
+		if p.Severity == severityIgnored || p.Severity == severityBaselined {
+			continue
+		}
+		uri := fileURI(p.Position.Filename)
+		diag := lspDiagnostic{
+			Range:    c.rangeOf(p.Position, p.End),
+			Severity: lspSeverity(p.Severity),
+			Code:     p.Category,
+			Source:   "staticcheck",
+			Message:  p.Message,
+		}
+		for _, rel := range p.Related {
+			if !rel.Position.IsValid() {
+				continue
+			}
+			diag.RelatedInformation = append(diag.RelatedInformation, lspRelatedInformation{
+				Location: lspLocation{
+					URI:   fileURI(rel.Position.Filename),
+					Range: c.rangeOf(rel.Position, rel.End),
+				},
+				Message: rel.Message,
+			})
+		}
+		e := lspEntry{diag: diag}
+		for _, fix := range p.SuggestedFixed {
+			edit := lspWorkspaceEdit{Changes: map[string][]lspTextEdit{}}
+			for _, te := range fix.TextEdits {
+				euri := fileURI(te.Position.Filename)
+				edit.Changes[euri] = append(edit.Changes[euri], lspTextEdit{
+					Range:   c.rangeOf(te.Position, te.End),
+					NewText: string(te.NewText),
+				})
+			}
+			e.actions = append(e.actions, lspCodeAction{
+				Title:       fix.Message,
+				Kind:        "quickfix",
+				Diagnostics: []lspDiagnostic{diag},
+				Edit:        edit,
+			})
+		}
+		entries[uri] = append(entries[uri], e)
+	}
+
+	s.mu.Lock()
+	var uris []string
+	for uri := range s.entries {
+		if _, ok := entries[uri]; !ok {
+			// Clear diagnostics that have been fixed
+			uris = append(uris, uri)
+		}
+	}
+	for uri := range entries {
+		uris = append(uris, uri)
+	}
+	s.entries = entries
+	s.mu.Unlock()
+
+	sort.Strings(uris)
+	for _, uri := range uris {
+		diags := make([]lspDiagnostic, len(entries[uri]))
+		for i, e := range entries[uri] {
+			diags[i] = e.diag
+		}
+		s.send(lspNotification{
+			JSONRPC: "2.0",
+			Method:  "textDocument/publishDiagnostics",
+			Params:  lspPublishDiagnosticsParams{URI: uri, Diagnostics: diags},
+		})
+	}
+}
+
+func lspSeverity(sev severity) int {
+	switch sev {
+	case severityWarning:
+		return lspSeverityWarning
+	case severityInfo:
+		return lspSeverityInformation
+	case severityHint:
+		return lspSeverityHint
+	default:
+		return lspSeverityError
+	}
+}
+
+func (s *lspServer) send(msg interface{}) {
+	s.outMu.Lock()
+	defer s.outMu.Unlock()
+	if err := writeLSPMessage(s.out, msg); err != nil {
+		fmt.Fprintln(s.log, "couldn't write message:", err)
+	}
+}
+
+func (s *lspServer) replyError(id *json.RawMessage, code int, msg string) {
+	s.send(lspErrorResponse{JSONRPC: "2.0", ID: id, Error: lspError{Code: code, Message: msg}})
+}
+
+// lspConverter converts positions to LSP positions, which are
+// zero-based and count characters in UTF-16 code units. It caches the
+// lines of source files.
+type lspConverter struct {
+	lines map[string][][]byte
+}
+
+func (c *lspConverter) position(pos token.Position) lspPosition {
+	if c.lines == nil {
+		c.lines = map[string][][]byte{}
+	}
+	lines, ok := c.lines[pos.Filename]
+	if !ok {
+		if b, err := ioutil.ReadFile(pos.Filename); err == nil {
+			lines = bytes.Split(b, []byte("\n"))
+		}
+		c.lines[pos.Filename] = lines
+	}
+	p := lspPosition{Line: pos.Line - 1, Character: pos.Column - 1}
+	if p.Line < 0 {
+		p.Line = 0
+	}
+	if p.Character < 0 {
+		p.Character = 0
+	}
+	if p.Line < len(lines) && p.Character <= len(lines[p.Line]) {
+		p.Character = len(utf16.Encode([]rune(string(lines[p.Line][:p.Character]))))
+	}
+	return p
+}
+
+func (c *lspConverter) rangeOf(pos, end token.Position) lspRange {
+	r := lspRange{Start: c.position(pos)}
+	if end.IsValid() && end.Filename == pos.Filename {
+		r.End = c.position(end)
+	} else {
+		r.End = r.Start
+	}
+	return r
+}
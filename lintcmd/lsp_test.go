@@ -0,0 +1,230 @@
+package lintcmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"go/token"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"honnef.co/go/tools/lintcmd/runner"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+func TestLSPConverter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "staticcheck")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "a.go")
+	if err := ioutil.WriteFile(path, []byte("package a\n\nvar s = \"😀\" + x\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	c := &lspConverter{}
+	// x is at byte column 17, but the emoji is two UTF-16 code units
+	// instead of four bytes.
+	got := c.rangeOf(
+		token.Position{Filename: path, Line: 3, Column: 17},
+		token.Position{Filename: path, Line: 3, Column: 18},
+	)
+	want := lspRange{Start: lspPosition{2, 14}, End: lspPosition{2, 15}}
+	if got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+type lspClient struct {
+	t *testing.T
+	w io.Writer
+	r *bufio.Reader
+}
+
+func (c *lspClient) send(id int, method string, params interface{}) {
+	msg := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  method,
+		"params":  params,
+	}
+	if id != 0 {
+		msg["id"] = id
+	}
+	if err := writeLSPMessage(c.w, msg); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+func (c *lspClient) receive(v interface{}) {
+	b, err := readLSPMessage(c.r)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+func TestLSPServer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "staticcheck")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "a.go")
+	if err := ioutil.WriteFile(path, []byte("package a\n\nvar x = 1\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	uri := fileURI(path)
+
+	checks := []*analysis.Analyzer{{Name: "XX1000", Doc: "Checks for x"}}
+	lint := func(dirs []string) ([]problem, []string, error) {
+		if !reflect.DeepEqual(dirs, []string{dir}) {
+			t.Errorf("got directories %v, want %v", dirs, []string{dir})
+		}
+		pos := token.Position{Filename: path, Line: 3, Column: 5}
+		end := token.Position{Filename: path, Line: 3, Column: 6}
+		return []problem{
+			{
+				Diagnostic: runner.Diagnostic{
+					Position: pos,
+					End:      end,
+					Category: "XX1000",
+					Message:  "x is bad",
+					SuggestedFixed: []runner.SuggestedFix{{
+						Message:   "Rename x to y",
+						TextEdits: []runner.TextEdit{{Position: pos, End: end, NewText: []byte("y")}},
+					}},
+				},
+				Severity: severityWarning,
+			},
+			{
+				Diagnostic: runner.Diagnostic{
+					Position: pos,
+					Category: "XX1000",
+					Message:  "this problem has been ignored",
+				},
+				Severity: severityIgnored,
+			},
+		}, nil, nil
+	}
+
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	s := newLSPServer(checks, lint, outW, ioutil.Discard)
+	type result struct {
+		ok  bool
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		ok, err := s.serve(inR)
+		done <- result{ok, err}
+	}()
+	c := &lspClient{t: t, w: inW, r: bufio.NewReader(outR)}
+
+	c.send(1, "initialize", map[string]interface{}{})
+	var init struct {
+		ID     int                 `json:"id"`
+		Result lspInitializeResult `json:"result"`
+	}
+	c.receive(&init)
+	if init.ID != 1 || !init.Result.Capabilities.CodeActionProvider || !init.Result.Capabilities.HoverProvider {
+		t.Fatalf("unexpected response to initialize: %+v", init)
+	}
+
+	c.send(0, "textDocument/didOpen", map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri, "languageId": "go", "version": 1, "text": ""},
+	})
+	var pub struct {
+		Method string                      `json:"method"`
+		Params lspPublishDiagnosticsParams `json:"params"`
+	}
+	c.receive(&pub)
+	if pub.Method != "textDocument/publishDiagnostics" || pub.Params.URI != uri {
+		t.Fatalf("unexpected notification: %+v", pub)
+	}
+	rng := lspRange{Start: lspPosition{2, 4}, End: lspPosition{2, 5}}
+	wantDiags := []lspDiagnostic{{
+		Range:    rng,
+		Severity: lspSeverityWarning,
+		Code:     "XX1000",
+		Source:   "staticcheck",
+		Message:  "x is bad",
+	}}
+	if !reflect.DeepEqual(pub.Params.Diagnostics, wantDiags) {
+		t.Errorf("got diagnostics %+v, want %+v", pub.Params.Diagnostics, wantDiags)
+	}
+
+	c.send(2, "textDocument/codeAction", map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri},
+		"range":        lspRange{Start: lspPosition{2, 0}, End: lspPosition{2, 0}},
+		"context":      map[string]interface{}{"diagnostics": []interface{}{}},
+	})
+	var actions struct {
+		ID     int             `json:"id"`
+		Result []lspCodeAction `json:"result"`
+	}
+	c.receive(&actions)
+	if len(actions.Result) != 0 {
+		t.Errorf("got code actions for a range without diagnostics: %+v", actions.Result)
+	}
+
+	c.send(3, "textDocument/codeAction", map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri},
+		"range":        lspRange{Start: lspPosition{2, 4}, End: lspPosition{2, 4}},
+		"context":      map[string]interface{}{"diagnostics": []interface{}{}},
+	})
+	c.receive(&actions)
+	wantActions := []lspCodeAction{{
+		Title:       "Rename x to y",
+		Kind:        "quickfix",
+		Diagnostics: wantDiags,
+		Edit: lspWorkspaceEdit{Changes: map[string][]lspTextEdit{
+			uri: {{Range: rng, NewText: "y"}},
+		}},
+	}}
+	if actions.ID != 3 || !reflect.DeepEqual(actions.Result, wantActions) {
+		t.Errorf("got code actions %+v, want %+v", actions.Result, wantActions)
+	}
+
+	c.send(4, "textDocument/hover", map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri},
+		"position":     lspPosition{2, 4},
+	})
+	var hover struct {
+		ID     int      `json:"id"`
+		Result lspHover `json:"result"`
+	}
+	c.receive(&hover)
+	if !strings.Contains(hover.Result.Contents.Value, "Checks for x") || hover.Result.Range != rng {
+		t.Errorf("unexpected hover %+v", hover.Result)
+	}
+
+	c.send(5, "workspace/symbol", map[string]interface{}{"query": "x"})
+	var unsupported struct {
+		ID    int      `json:"id"`
+		Error lspError `json:"error"`
+	}
+	c.receive(&unsupported)
+	if unsupported.ID != 5 || unsupported.Error.Code != lspMethodNotFound {
+		t.Errorf("unexpected response to unsupported method: %+v", unsupported)
+	}
+
+	c.send(6, "shutdown", nil)
+	var shutdown struct {
+		ID int `json:"id"`
+	}
+	c.receive(&shutdown)
+	c.send(0, "exit", nil)
+	res := <-done
+	if res.err != nil || !res.ok {
+		t.Errorf("serve returned (%t, %v), want (true, nil)", res.ok, res.err)
+	}
+}
@@ -0,0 +1,68 @@
+package lintcmd
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/tools/go/buildutil"
+)
+
+// A buildConfig is one configuration of a -matrix run. Empty fields
+// use the values of the environment.
+type buildConfig struct {
+	GOOS   string
+	GOARCH string
+	// Build tags, in addition to the ones passed via -tags
+	Tags string
+}
+
+func (bc buildConfig) String() string {
+	var s string
+	if bc.GOOS != "" {
+		s = bc.GOOS + "/" + bc.GOARCH
+	}
+	if bc.Tags != "" {
+		s += ";tags=" + bc.Tags
+	}
+	return s
+}
+
+// parseMatrix parses the value of the -matrix flag, which is a
+// comma-separated list of configurations of the form
+// [GOOS/GOARCH][;tags=TAGS]. Tags are separated by spaces, as in
+// -tags.
+func parseMatrix(s string) ([]buildConfig, error) {
+	var out []buildConfig
+	seen := map[buildConfig]bool{}
+	for _, entry := range strings.Split(s, ",") {
+		fields := strings.Split(strings.TrimSpace(entry), ";")
+		var bc buildConfig
+		if platform := fields[0]; platform != "" {
+			idx := strings.IndexByte(platform, '/')
+			if idx <= 0 || idx == len(platform)-1 {
+				return nil, fmt.Errorf("invalid configuration %q: platform must be of the form GOOS/GOARCH", entry)
+			}
+			bc.GOOS, bc.GOARCH = platform[:idx], platform[idx+1:]
+		}
+		for _, field := range fields[1:] {
+			kv := strings.SplitN(field, "=", 2)
+			if len(kv) != 2 || kv[0] != "tags" {
+				return nil, fmt.Errorf("invalid configuration %q: unknown option %q", entry, field)
+			}
+			tf := buildutil.TagsFlag{}
+			if err := tf.Set(kv[1]); err != nil {
+				return nil, fmt.Errorf("invalid configuration %q: %s", entry, err)
+			}
+			bc.Tags = strings.Join(tf, " ")
+		}
+		if bc == (buildConfig{}) {
+			return nil, fmt.Errorf("invalid configuration %q: empty configuration", entry)
+		}
+		if seen[bc] {
+			continue
+		}
+		seen[bc] = true
+		out = append(out, bc)
+	}
+	return out, nil
+}
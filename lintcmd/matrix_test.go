@@ -0,0 +1,55 @@
+package lintcmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMatrix(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []buildConfig
+		err  bool
+	}{
+		{
+			in: "linux/amd64,windows/amd64,darwin/arm64;tags=integration",
+			want: []buildConfig{
+				{GOOS: "linux", GOARCH: "amd64"},
+				{GOOS: "windows", GOARCH: "amd64"},
+				{GOOS: "darwin", GOARCH: "arm64", Tags: "integration"},
+			},
+		},
+		{
+			in: ";tags=foo bar, linux/386",
+			want: []buildConfig{
+				{Tags: "foo bar"},
+				{GOOS: "linux", GOARCH: "386"},
+			},
+		},
+		{
+			in:   "linux/amd64,linux/amd64",
+			want: []buildConfig{{GOOS: "linux", GOARCH: "amd64"}},
+		},
+		{in: "linux", err: true},
+		{in: "linux/", err: true},
+		{in: "linux/amd64,", err: true},
+		{in: "linux/amd64;cgo=1", err: true},
+		{in: "linux/amd64;tags='foo", err: true},
+	}
+	for _, tt := range tests {
+		got, err := parseMatrix(tt.in)
+		if tt.err {
+			if err == nil {
+				t.Errorf("parseMatrix(%q) succeeded, want error", tt.in)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseMatrix(%q) failed: %s", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseMatrix(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package lintcmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"honnef.co/go/tools/lintcmd/runner"
+)
+
+// writeProfile prints the report of a profile in the given format,
+// which is either "text" or "json". Unlike the other JSON outputs,
+// the JSON format is a single object, with durations in nanoseconds.
+func writeProfile(w io.Writer, p *runner.Profile, format string) error {
+	analyzers := p.Analyzers()
+	packages := p.Packages()
+
+	var hits, misses int
+	var source, export time.Duration
+	for _, pp := range packages {
+		hits += pp.CacheHits
+		misses += pp.CacheMisses
+		source += pp.Source
+		export += pp.Export
+	}
+
+	switch format {
+	case "text":
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintf(tw, "Loading package graph:\t%s\n", p.Graph())
+		fmt.Fprintf(tw, "Loading packages:\t%s (source), %s (export data)\n", source, export)
+		fmt.Fprintf(tw, "Cache:\t%d hits, %d misses\n", hits, misses)
+		fmt.Fprintln(tw)
+		fmt.Fprintln(tw, "ANALYZER\tRUNS\tWALL\tCPU\tALLOC")
+		for _, ap := range analyzers {
+			fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\n", ap.Name, ap.Runs, ap.Wall, ap.CPU, formatBytes(ap.Alloc))
+		}
+		fmt.Fprintln(tw)
+		fmt.Fprintln(tw, "PACKAGE\tCACHE\tSOURCE\tEXPORT\tWALL\tCPU\tALLOC")
+		for _, pp := range packages {
+			var cache []string
+			if pp.CacheHits > 0 {
+				cache = append(cache, fmt.Sprintf("%d hit", pp.CacheHits))
+			}
+			if pp.CacheMisses > 0 {
+				cache = append(cache, fmt.Sprintf("%d miss", pp.CacheMisses))
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				pp.ID, strings.Join(cache, ", "), pp.Source, pp.Export, pp.Analysis.Wall, pp.Analysis.CPU, formatBytes(pp.Analysis.Alloc))
+		}
+		return tw.Flush()
+	case "json":
+		type measurement struct {
+			Runs  int    `json:"runs"`
+			Wall  int64  `json:"wall"`
+			CPU   int64  `json:"cpu"`
+			Alloc uint64 `json:"alloc"`
+		}
+		toJSON := func(m runner.Measurement) measurement {
+			return measurement{
+				Runs:  m.Runs,
+				Wall:  m.Wall.Nanoseconds(),
+				CPU:   m.CPU.Nanoseconds(),
+				Alloc: m.Alloc,
+			}
+		}
+		type analyzer struct {
+			Name string `json:"name"`
+			measurement
+		}
+		type pkg struct {
+			ID          string      `json:"id"`
+			Source      int64       `json:"source"`
+			Export      int64       `json:"export"`
+			CacheHits   int         `json:"cache_hits"`
+			CacheMisses int         `json:"cache_misses"`
+			Analysis    measurement `json:"analysis"`
+		}
+		report := struct {
+			Graph       int64      `json:"graph"`
+			Source      int64      `json:"source"`
+			Export      int64      `json:"export"`
+			CacheHits   int        `json:"cache_hits"`
+			CacheMisses int        `json:"cache_misses"`
+			Analyzers   []analyzer `json:"analyzers"`
+			Packages    []pkg      `json:"packages"`
+		}{
+			Graph:       p.Graph().Nanoseconds(),
+			Source:      source.Nanoseconds(),
+			Export:      export.Nanoseconds(),
+			CacheHits:   hits,
+			CacheMisses: misses,
+			Analyzers:   make([]analyzer, len(analyzers)),
+			Packages:    make([]pkg, len(packages)),
+		}
+		for i, ap := range analyzers {
+			report.Analyzers[i] = analyzer{ap.Name, toJSON(ap.Measurement)}
+		}
+		for i, pp := range packages {
+			report.Packages[i] = pkg{
+				ID:          pp.ID,
+				Source:      pp.Source.Nanoseconds(),
+				Export:      pp.Export.Nanoseconds(),
+				CacheHits:   pp.CacheHits,
+				CacheMisses: pp.CacheMisses,
+				Analysis:    toJSON(pp.Analysis),
+			}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "\t")
+		return enc.Encode(report)
+	default:
+		return fmt.Errorf("unsupported output format %q for -debug.profile-report", format)
+	}
+}
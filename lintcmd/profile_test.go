@@ -0,0 +1,83 @@
+package lintcmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"honnef.co/go/tools/config"
+	"honnef.co/go/tools/lintcmd/runner"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/packages"
+)
+
+func TestProfileReport(t *testing.T) {
+	l, err := newLinter(config.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Checkers = []*analysis.Analyzer{{
+		Name: "XX9999",
+		Doc:  "does nothing",
+		Run:  func(*analysis.Pass) (interface{}, error) { return nil, nil },
+	}}
+	profile := &runner.Profile{}
+	l.Runner.Profile = profile
+	cfg := &packages.Config{
+		Env: append(os.Environ(), "GOPATH="+testdata(), "GO111MODULE=off"),
+	}
+	// The second run finds the package in the cache.
+	for i := 0; i < 2; i++ {
+		if _, _, err := l.Lint([]*packages.Config{cfg}, []string{"Test"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pkgs := profile.Packages()
+	if len(pkgs) != 1 || pkgs[0].ID != "Test" {
+		t.Fatalf("got packages %v, want only Test", pkgs)
+	}
+	if pkgs[0].CacheHits+pkgs[0].CacheMisses != 2 || pkgs[0].CacheHits == 0 {
+		t.Errorf("got %d cache hits and %d misses, want 2 lookups with at least one hit", pkgs[0].CacheHits, pkgs[0].CacheMisses)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := writeProfile(buf, profile, "json"); err != nil {
+		t.Fatal(err)
+	}
+	var report struct {
+		Analyzers []struct {
+			Name string `json:"name"`
+			Runs int    `json:"runs"`
+		} `json:"analyzers"`
+		Packages []struct {
+			ID string `json:"id"`
+		} `json:"packages"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Packages) != 1 || report.Packages[0].ID != "Test" {
+		t.Errorf("unexpected packages in report: %s", buf)
+	}
+	for _, a := range report.Analyzers {
+		if a.Runs == 0 {
+			t.Errorf("analyzer %s has no runs", a.Name)
+		}
+	}
+
+	buf.Reset()
+	if err := writeProfile(buf, profile, "text"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "\nPACKAGE") || !strings.Contains(buf.String(), "\nTest ") {
+		t.Errorf("unexpected text report:\n%s", buf)
+	}
+
+	if err := writeProfile(buf, profile, "sarif"); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+}
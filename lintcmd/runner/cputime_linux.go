@@ -0,0 +1,17 @@
+package runner
+
+import (
+	"syscall"
+	"time"
+)
+
+// threadCPUTime returns the CPU time used by the current OS thread.
+// Callers must lock the goroutine to its thread for the result to be
+// meaningful.
+func threadCPUTime() time.Duration {
+	var ru syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_THREAD, &ru); err != nil {
+		return 0
+	}
+	return time.Duration(ru.Utime.Nano() + ru.Stime.Nano())
+}
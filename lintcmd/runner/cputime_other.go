@@ -0,0 +1,9 @@
+// +build !linux
+
+package runner
+
+import "time"
+
+// threadCPUTime returns the CPU time used by the current OS thread.
+// It is not supported on this platform and always returns zero.
+func threadCPUTime() time.Duration { return 0 }
@@ -0,0 +1,64 @@
+package runner
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+)
+
+// cacheFormat is the version of the format of cached facts and
+// results. Cached files consist of a byte holding the version,
+// followed by gzip-compressed gob data.
+//
+// Version 0 was the uncompressed gob data.
+const cacheFormat byte = 1
+
+// newCacheWriter writes the header of cached data to w and returns a
+// writer that compresses the data written to it. The writer must be
+// closed to flush the data.
+func newCacheWriter(w io.Writer) (io.WriteCloser, error) {
+	if _, err := w.Write([]byte{cacheFormat}); err != nil {
+		return nil, err
+	}
+	// Cached data is written once but read many times, and
+	// decompression speed barely depends on the level. BestSpeed
+	// still achieves most of the possible savings.
+	return gzip.NewWriterLevel(w, gzip.BestSpeed)
+}
+
+type cacheFile struct {
+	*gzip.Reader
+	f *os.File
+}
+
+func (f *cacheFile) Close() error {
+	f.Reader.Close()
+	return f.f.Close()
+}
+
+// openCacheFile opens a file of cached data written via
+// newCacheWriter, returning a reader of the decompressed data.
+func openCacheFile(name string) (io.ReadCloser, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	br := bufio.NewReader(f)
+	version, err := br.ReadByte()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if version != cacheFormat {
+		f.Close()
+		return nil, fmt.Errorf("%s: unsupported format version %d, want %d", name, version, cacheFormat)
+	}
+	zr, err := gzip.NewReader(br)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &cacheFile{zr, f}, nil
+}
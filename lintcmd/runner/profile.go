@@ -0,0 +1,207 @@
+package runner
+
+import (
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"honnef.co/go/tools/go/loader"
+)
+
+// A Measurement is the cost of running one or more analyzers.
+//
+// CPU time is the CPU time of the OS thread running the analyzer and
+// is only available on Linux. Allocations are measured for the whole
+// process, which includes allocations made by concurrently running
+// analyzers; they are only exact when running with a single worker.
+type Measurement struct {
+	// The number of analyzer runs that have been measured
+	Runs  int
+	Wall  time.Duration
+	CPU   time.Duration
+	Alloc uint64
+}
+
+func (m *Measurement) add(o Measurement) {
+	m.Runs += o.Runs
+	m.Wall += o.Wall
+	m.CPU += o.CPU
+	m.Alloc += o.Alloc
+}
+
+// An AnalyzerProfile aggregates the runs of an analyzer on all
+// packages.
+type AnalyzerProfile struct {
+	Name string
+	Measurement
+}
+
+// A PackageProfile aggregates the work done for a package.
+type PackageProfile struct {
+	ID string
+	// Time spent parsing and type-checking the package
+	Source time.Duration
+	// Time spent loading the export data of the package's
+	// dependencies
+	Export time.Duration
+	// How often the package's facts and results were found in, or
+	// missing from, the cache
+	CacheHits   int
+	CacheMisses int
+	// All analyzer runs on the package
+	Analysis Measurement
+}
+
+// A Profile collects measurements of one or more runs, aggregated per
+// analyzer and per package. The zero value is ready to use, and a nil
+// *Profile measures nothing.
+type Profile struct {
+	mu sync.Mutex
+	// Time spent loading package graphs
+	graph     time.Duration
+	analyzers map[string]*AnalyzerProfile
+	packages  map[string]*PackageProfile
+}
+
+// sample is a snapshot of the resources used so far.
+type sample struct {
+	wall  time.Time
+	cpu   time.Duration
+	alloc uint64
+}
+
+func takeSample() sample {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	return sample{
+		wall:  time.Now(),
+		cpu:   threadCPUTime(),
+		alloc: ms.TotalAlloc,
+	}
+}
+
+// since returns the resources used since s was taken.
+func (s sample) since() Measurement {
+	now := takeSample()
+	return Measurement{
+		Runs:  1,
+		Wall:  now.wall.Sub(s.wall),
+		CPU:   now.cpu - s.cpu,
+		Alloc: now.alloc - s.alloc,
+	}
+}
+
+// pkg returns the profile of the package identified by id. The caller
+// must hold p.mu.
+func (p *Profile) pkg(id string) *PackageProfile {
+	pp, ok := p.packages[id]
+	if !ok {
+		if p.packages == nil {
+			p.packages = map[string]*PackageProfile{}
+		}
+		pp = &PackageProfile{ID: id}
+		p.packages[id] = pp
+	}
+	return pp
+}
+
+func (p *Profile) measureGraph(d time.Duration) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.graph += d
+}
+
+func (p *Profile) measureLoad(spec *loader.PackageSpec, stats loader.Stats) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	pp := p.pkg(spec.ID)
+	pp.Source += stats.Source
+	for _, d := range stats.Export {
+		pp.Export += d
+	}
+}
+
+func (p *Profile) measureCache(spec *loader.PackageSpec, hit bool) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	pp := p.pkg(spec.ID)
+	if hit {
+		pp.CacheHits++
+	} else {
+		pp.CacheMisses++
+	}
+}
+
+func (p *Profile) measureAnalyzer(name string, spec *loader.PackageSpec, m Measurement) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ap, ok := p.analyzers[name]
+	if !ok {
+		if p.analyzers == nil {
+			p.analyzers = map[string]*AnalyzerProfile{}
+		}
+		ap = &AnalyzerProfile{Name: name}
+		p.analyzers[name] = ap
+	}
+	ap.add(m)
+	p.pkg(spec.ID).Analysis.add(m)
+}
+
+// Graph returns the time spent loading package graphs.
+func (p *Profile) Graph() time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.graph
+}
+
+// Analyzers returns the profiles of all analyzers that have run,
+// sorted by descending wall time.
+func (p *Profile) Analyzers() []AnalyzerProfile {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := make([]AnalyzerProfile, 0, len(p.analyzers))
+	for _, ap := range p.analyzers {
+		out = append(out, *ap)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Wall != out[j].Wall {
+			return out[i].Wall > out[j].Wall
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// Packages returns the profiles of all packages that have been
+// processed, sorted by descending total time.
+func (p *Profile) Packages() []PackageProfile {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := make([]PackageProfile, 0, len(p.packages))
+	for _, pp := range p.packages {
+		out = append(out, *pp)
+	}
+	total := func(pp PackageProfile) time.Duration {
+		return pp.Source + pp.Export + pp.Analysis.Wall
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if ti, tj := total(out[i]), total(out[j]); ti != tj {
+			return ti > tj
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out
+}
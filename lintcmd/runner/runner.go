@@ -42,7 +42,8 @@
 //
 // Actions are executed in parallel where the dependency graph allows.
 // Overall parallelism is bounded by a semaphore, sized according to
-// runtime.NumCPU(). Each concurrently processed package takes up a
+// runtime.NumCPU() by default (see Runner.SetWorkers). Each
+// concurrently processed package takes up a
 // token, as does each analyzer – but a package can always execute at
 // least one analyzer, using the package's token.
 //
@@ -56,6 +57,12 @@
 // the dependency graph. A lot of inter-connected packages will see
 // less parallelism than a lot of independent packages.
 //
+// To bound memory usage more directly, the runner can be given a
+// memory budget (see Runner.MaxMemory). While heap usage exceeds the
+// budget, no new packages get scheduled, unless no packages are being
+// processed at all. Packages that are already being processed
+// continue, and free memory once they're done.
+//
 // Caching
 //
 // The runner caches facts, directives and diagnostics in a
@@ -74,46 +81,26 @@
 // times over, but it greatly reduces peak memory usage, as an
 // arbitrary amount of time may pass between analyzing a dependency
 // and its dependent, during which other packages will be processed.
-package runner
-
-// OPT(dh): we could reduce disk storage usage of cached data by
-// compressing it, either directly at the cache layer, or by feeding
-// compressed data to the cache. Of course doing so may negatively
-// affect CPU usage, and there are lower hanging fruit, such as
-// needing to cache less data in the first place.
-
-// OPT(dh): right now, each package is analyzed completely
-// independently. Each package loads all of its dependencies from
-// export data and cached facts. If we have two packages A and B,
-// which both depend on C, and which both get analyzed in parallel,
-// then C will be loaded twice. This wastes CPU time and memory. It
-// would be nice if we could reuse a single C for the analysis of both
-// A and B.
-//
-// We can't reuse the actual types.Package or facts, because each
-// package gets its own token.FileSet. Sharing a global FileSet has
-// several drawbacks, including increased memory usage and running the
-// risk of running out of FileSet address space.
 //
-// We could however avoid loading the same raw export data from disk
-// twice, as well as deserializing gob data twice. One possible
-// solution would be a duplicate-suppressing in-memory cache that
-// caches data for a limited amount of time. When the same package
-// needs to be loaded twice in close succession, we can reuse work,
-// without holding unnecessary data in memory for an extended period
-// of time.
+// Cached data is compressed, trading a little CPU time for a lot of
+// disk space. The format of cached data is versioned (see
+// cacheFormat), and the version is part of the cache keys, so that
+// data written by older versions is never used.
 //
-// We would likely need to do extensive benchmarking to figure out how
-// long to keep data around to find a sweetspot where we reduce CPU
-// load without increasing memory usage.
-//
-// We can probably populate the cache after we've analyzed a package,
-// on the assumption that it will have to be loaded again in the near
-// future.
+// Packages that are being processed in parallel often share
+// dependencies. To avoid reading the same export data from disk and
+// decoding the same facts several times over, both are kept in
+// short-lived in-memory caches (see memcache). Entries expire after a
+// few seconds, are bounded in total size (a fraction of the memory
+// budget, if there is one), and are dropped when the memory budget is
+// exceeded, so that reusing work doesn't come at the
+// cost of holding on to data for longer than necessary.
+package runner
 
 import (
 	"encoding/gob"
 	"fmt"
+	"go/ast"
 	"go/token"
 	"go/types"
 	"io"
@@ -125,12 +112,14 @@ import (
 	"strings"
 	"sync/atomic"
 	"time"
+	"unsafe"
 
 	"honnef.co/go/tools/analysis/lint"
 	"honnef.co/go/tools/analysis/report"
 	"honnef.co/go/tools/config"
 	"honnef.co/go/tools/go/loader"
 	"honnef.co/go/tools/internal/cache"
+	"honnef.co/go/tools/internal/memcache"
 	tsync "honnef.co/go/tools/internal/sync"
 	"honnef.co/go/tools/unused"
 
@@ -190,14 +179,22 @@ type SerializedDirective struct {
 	DirectivePosition token.Position
 	// The position of the node that the comment is attached to
 	NodePosition token.Position
+	// The end of the node that the comment is attached to
+	NodeEnd token.Position
+	// Whether the comment is attached to the file as a whole, which
+	// is the case for the package's documentation
+	FileNode bool
 }
 
 func serializeDirective(dir lint.Directive, fset *token.FileSet) SerializedDirective {
+	_, isFile := dir.Node.(*ast.File)
 	return SerializedDirective{
 		Command:           dir.Command,
 		Arguments:         dir.Arguments,
 		DirectivePosition: report.DisplayPosition(fset, dir.Directive.Pos()),
 		NodePosition:      report.DisplayPosition(fset, dir.Node.Pos()),
+		NodeEnd:           report.DisplayPosition(fset, dir.Node.End()),
+		FileNode:          isFile,
 	}
 }
 
@@ -215,7 +212,7 @@ func (r Result) Load() (ResultData, error) {
 		// this package was only a dependency
 		return ResultData{}, nil
 	}
-	f, err := os.Open(r.results)
+	f, err := openCacheFile(r.results)
 	if err != nil {
 		return ResultData{}, fmt.Errorf("failed loading result: %w", err)
 	}
@@ -329,11 +326,55 @@ func (act *analyzerAction) String() string {
 type Runner struct {
 	Stats     Stats
 	GoVersion int
+	// MaxMemory is the memory budget in bytes. While the heap is
+	// larger than the budget, no new packages get scheduled. Zero
+	// means no limit.
+	MaxMemory uint64
+	// If non-nil, Profile collects measurements of the work done by
+	// Run. Measuring allocations briefly stops the world for every
+	// analyzer that runs, which slows down the run.
+	Profile *Profile
 
 	// Config that gets merged with per-package configs
 	cfg       config.Config
 	cache     *cache.Cache
 	semaphore tsync.Semaphore
+	// In-memory caches of export data and decoded facts, keyed by
+	// cache IDs
+	exports *memcache.Cache
+	facts   *memcache.Cache
+	// Set to 1 once waitForMemory has purged the in-memory caches and
+	// forced a garbage collection, and reset when heap usage drops
+	// back under the memory budget
+	collected uint32
+}
+
+const (
+	// How long export data and facts are kept in memory after
+	// they've been loaded. Dependents of a package are usually
+	// processed soon after one another, so this can be short.
+	memoryCacheTTL = 5 * time.Second
+	// Size of each in-memory cache when there is no memory budget.
+	// Without a budget, nothing purges the caches when memory gets
+	// tight, so they have to be small enough not to raise peak
+	// memory usage noticeably. A few MiB still hold the export data
+	// and facts of the most commonly shared dependencies.
+	defaultMemoryCacheSize = 4 * 1024 * 1024
+	// Upper bound on the size of each in-memory cache
+	maxMemoryCacheSize = 32 * 1024 * 1024
+)
+
+// memoryCacheSize returns the maximum size of each in-memory cache.
+// With a memory budget, the caches together use no more than an
+// eighth of it, leaving the rest to the packages being analyzed.
+func (r *Runner) memoryCacheSize() int64 {
+	if r.MaxMemory == 0 {
+		return defaultMemoryCacheSize
+	}
+	if r.MaxMemory/16 < maxMemoryCacheSize {
+		return int64(r.MaxMemory / 16)
+	}
+	return maxMemoryCacheSize
 }
 
 type subrunner struct {
@@ -350,13 +391,68 @@ func New(cfg config.Config) (*Runner, error) {
 		return nil, err
 	}
 
+	exports := memcache.New(memoryCacheTTL, defaultMemoryCacheSize)
+	facts := memcache.New(memoryCacheTTL, defaultMemoryCacheSize)
 	return &Runner{
+		Stats: Stats{
+			exports: exports,
+			facts:   facts,
+		},
 		cfg:       cfg,
 		cache:     cache,
 		semaphore: tsync.NewSemaphore(runtime.NumCPU()),
+		exports:   exports,
+		facts:     facts,
 	}, nil
 }
 
+// SetWorkers sets the maximum number of concurrently running workers.
+// It must not be called while Run is running.
+func (r *Runner) SetWorkers(n int) {
+	r.semaphore = tsync.NewSemaphore(n)
+}
+
+// waitForMemory blocks while heap usage exceeds the memory budget and
+// other packages are being processed, which will free memory once
+// they're done.
+func (r *Runner) waitForMemory() {
+	if r.MaxMemory == 0 {
+		return
+	}
+	throttled := false
+	for {
+		var ms runtime.MemStats
+		runtime.ReadMemStats(&ms)
+		r.Stats.setHeapAlloc(ms.HeapAlloc)
+		if ms.HeapAlloc <= r.MaxMemory {
+			atomic.StoreUint32(&r.collected, 0)
+			break
+		}
+		if r.semaphore.Len() == 0 {
+			break
+		}
+		// The heap may consist of cached data that we can do
+		// without, or mostly of garbage that hasn't been collected
+		// yet. A collection is expensive, so we only force one each
+		// time usage goes over budget, not for every package that
+		// gets scheduled while it stays there.
+		r.exports.Purge()
+		r.facts.Purge()
+		if atomic.CompareAndSwapUint32(&r.collected, 0, 1) {
+			runtime.GC()
+			continue
+		}
+		if !throttled {
+			throttled = true
+			r.Stats.throttle()
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if throttled {
+		r.Stats.unthrottle()
+	}
+}
+
 func newSubrunner(r *Runner, analyzers []*analysis.Analyzer) *subrunner {
 	analyzerNames := make([]string, len(analyzers))
 	for i, a := range analyzers {
@@ -484,15 +580,26 @@ func (r *subrunner) do(act action) error {
 	// checks.
 
 	// Config used for constructing the hash; this config doesn't have
-	// Checks populated, because we always run all checks.
+	// Checks populated, because we always run all checks. Severities,
+	// overrides and the pattern for reasons of ignore directives only
+	// matter when reporting problems, and excluded packages are never
+	// analyzed as initial packages.
 	hashCfg := a.cfg
 	hashCfg.Checks = nil
+	hashCfg.Severity = nil
+	hashCfg.Overrides = nil
+	hashCfg.ExcludePaths = nil
+	hashCfg.IgnoreReasonPattern = ""
+	// Options contains pointers, which we mustn't hash.
+	hashCfg.Options = nil
 	// note that we don't hash staticcheck's version; it is set as the
 	// salt by a package main.
 	fmt.Fprintf(h, "cfg %#v\n", hashCfg)
+	fmt.Fprintf(h, "options %s\n", a.cfg.OptionsString())
 	fmt.Fprintf(h, "pkg %x\n", a.Package.Hash)
 	fmt.Fprintf(h, "analyzers %s\n", r.analyzerNames)
 	fmt.Fprintf(h, "go 1.%d\n", r.GoVersion)
+	fmt.Fprintf(h, "format %d\n", cacheFormat)
 
 	// OPT(dh): do we actually need to hash vetx? can we not assume
 	// that for identical inputs, staticcheck will produce identical
@@ -513,7 +620,9 @@ func (r *subrunner) do(act action) error {
 	if !a.factsOnly {
 		ids = append(ids, cache.Subkey(a.hash, "results"))
 	}
-	if err := getCachedFiles(r.cache, ids, []*string{&a.vetx, &a.results}); err != nil {
+	err := getCachedFiles(r.cache, ids, []*string{&a.vetx, &a.results})
+	r.Profile.measureCache(a.Package, err == nil)
+	if err != nil {
 		result, err := r.doUncached(a)
 		if err != nil {
 			return err
@@ -534,24 +643,14 @@ func (r *subrunner) do(act action) error {
 		// change to a package requires re-analyzing all dependents,
 		// even if the vetx data stayed the same. See also the note at
 		// the top of loader/hash.go.
-		tf, err := ioutil.TempFile("", "staticcheck")
-		if err != nil {
-			return err
-		}
-		defer tf.Close()
-		os.Remove(tf.Name())
-
-		enc := gob.NewEncoder(tf)
-		for _, gf := range result.facts {
-			if err := enc.Encode(gf); err != nil {
-				return fmt.Errorf("failed gob encoding data: %w", err)
+		a.vetx, err = r.writeCacheGob(a, "vetx", func(enc *gob.Encoder) error {
+			for _, gf := range result.facts {
+				if err := enc.Encode(gf); err != nil {
+					return err
+				}
 			}
-		}
-
-		if _, err := tf.Seek(0, io.SeekStart); err != nil {
-			return err
-		}
-		a.vetx, err = r.writeCacheReader(a, "vetx", tf)
+			return nil
+		})
 		if err != nil {
 			return err
 		}
@@ -568,7 +667,9 @@ func (r *subrunner) do(act action) error {
 
 		out.Diagnostics = result.diags
 		out.Unused = result.unused
-		a.results, err = r.writeCacheGob(a, "results", out)
+		a.results, err = r.writeCacheGob(a, "results", func(enc *gob.Encoder) error {
+			return enc.Encode(out)
+		})
 		if err != nil {
 			return err
 		}
@@ -595,16 +696,25 @@ func (r *Runner) writeCacheReader(a *packageAction, kind string, rs io.ReadSeeke
 	return r.cache.OutputFile(out), nil
 }
 
-func (r *Runner) writeCacheGob(a *packageAction, kind string, data interface{}) (string, error) {
+// writeCacheGob caches the gob data written by encode, in the format
+// described by cacheFormat.
+func (r *Runner) writeCacheGob(a *packageAction, kind string, encode func(*gob.Encoder) error) (string, error) {
 	f, err := ioutil.TempFile("", "staticcheck")
 	if err != nil {
 		return "", err
 	}
 	defer f.Close()
 	os.Remove(f.Name())
-	if err := gob.NewEncoder(f).Encode(data); err != nil {
+	w, err := newCacheWriter(f)
+	if err != nil {
+		return "", err
+	}
+	if err := encode(gob.NewEncoder(w)); err != nil {
 		return "", fmt.Errorf("failed gob encoding data: %w", err)
 	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
 	if _, err := f.Seek(0, io.SeekStart); err != nil {
 		return "", err
 	}
@@ -621,14 +731,11 @@ type packageActionResult struct {
 }
 
 func (r *subrunner) doUncached(a *packageAction) (packageActionResult, error) {
-	// OPT(dh): for a -> b; c -> b; if both a and b are being
-	// processed concurrently, we shouldn't load b's export data
-	// twice.
-
-	pkg, _, err := loader.Load(a.Package)
+	pkg, stats, err := loader.Load(a.Package, r.exports)
 	if err != nil {
 		return packageActionResult{}, err
 	}
+	r.Profile.measureLoad(a.Package, stats)
 
 	if len(pkg.Errors) > 0 {
 		// this handles errors that occured during type-checking the
@@ -678,26 +785,107 @@ func pkgPaths(root *types.Package) map[string]*types.Package {
 	return out
 }
 
+// factsSize estimates the memory used by decoded facts. The size of
+// the encoded facts would be a poor estimate: gob doesn't encode
+// headers and padding, and every decoded string is a separate
+// allocation.
+func factsSize(gfs []gobFact) int64 {
+	size := int64(cap(gfs)) * int64(unsafe.Sizeof(gobFact{}))
+	for _, gf := range gfs {
+		size += int64(len(gf.PkgPath) + len(gf.ObjPath))
+		size += referencedSize(reflect.ValueOf(gf.Fact))
+	}
+	return size
+}
+
+// referencedSize estimates the size of the memory that v refers to,
+// not counting v itself.
+func referencedSize(v reflect.Value) int64 {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return 0
+		}
+		e := v.Elem()
+		size := referencedSize(e)
+		if v.Kind() == reflect.Ptr {
+			size += int64(e.Type().Size())
+		}
+		return size
+	case reflect.String:
+		return int64(v.Len())
+	case reflect.Slice:
+		size := int64(v.Cap()) * int64(v.Type().Elem().Size())
+		for i := 0; i < v.Len(); i++ {
+			size += referencedSize(v.Index(i))
+		}
+		return size
+	case reflect.Array:
+		var size int64
+		for i := 0; i < v.Len(); i++ {
+			size += referencedSize(v.Index(i))
+		}
+		return size
+	case reflect.Struct:
+		var size int64
+		for i := 0; i < v.NumField(); i++ {
+			size += referencedSize(v.Field(i))
+		}
+		return size
+	case reflect.Map:
+		// Maps need roughly twice the space of their keys and
+		// values.
+		size := int64(v.Len()) * 2 * int64(v.Type().Key().Size()+v.Type().Elem().Size())
+		iter := v.MapRange()
+		for iter.Next() {
+			size += referencedSize(iter.Key()) + referencedSize(iter.Value())
+		}
+		return size
+	default:
+		return 0
+	}
+}
+
+// readFacts decodes the cached facts of dep. The returned facts are
+// shared via the in-memory cache and must not be modified.
+func (r *Runner) readFacts(dep *packageAction) ([]gobFact, error) {
+	v, err := r.facts.Get(cache.Subkey(dep.hash, "vetx"), func() (interface{}, int64, error) {
+		vetx, err := openCacheFile(dep.vetx)
+		if err != nil {
+			return nil, 0, err
+		}
+		defer vetx.Close()
+
+		dec := gob.NewDecoder(vetx)
+		var gfs []gobFact
+		for {
+			var gf gobFact
+			err := dec.Decode(&gf)
+			if err != nil {
+				if err == io.EOF {
+					break
+				}
+				return nil, 0, err
+			}
+			gfs = append(gfs, gf)
+		}
+		return gfs, factsSize(gfs), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.([]gobFact), nil
+}
+
 func (r *Runner) loadFacts(root *types.Package, dep *packageAction, objFacts map[objectFactKey]analysis.Fact, pkgFacts map[packageFactKey]analysis.Fact) error {
 	// Load facts of all imported packages
-	vetx, err := os.Open(dep.vetx)
+	gfs, err := r.readFacts(dep)
 	if err != nil {
 		return fmt.Errorf("failed loading cached facts: %w", err)
 	}
-	defer vetx.Close()
 
 	pathToPkg := pkgPaths(root)
-	dec := gob.NewDecoder(vetx)
-	for {
-		var gf gobFact
-		err := dec.Decode(&gf)
-		if err != nil {
-			if err == io.EOF {
-				break
-			}
-			return fmt.Errorf("failed loading cached facts: %w", err)
-		}
-
+	for _, gf := range gfs {
 		pkg, ok := pathToPkg[gf.PkgPath]
 		if !ok {
 			continue
@@ -773,7 +961,7 @@ type analyzerRunner struct {
 	depPkgFacts map[packageFactKey]analysis.Fact
 	factsOnly   bool
 
-	stats *Stats
+	profile *Profile
 }
 
 func (ar *analyzerRunner) do(act action) error {
@@ -920,9 +1108,18 @@ func (ar *analyzerRunner) do(act action) error {
 		},
 	}
 
-	t := time.Now()
+	var start sample
+	if ar.profile != nil {
+		// the thread's CPU time only reflects the analyzer if the
+		// analyzer runs on the same thread throughout
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+		start = takeSample()
+	}
 	res, err := a.Analyzer.Run(a.Pass)
-	ar.stats.measureAnalyzer(a.Analyzer, ar.pkg.PackageSpec, time.Since(t))
+	if ar.profile != nil {
+		ar.profile.measureAnalyzer(a.Analyzer.Name, ar.pkg.PackageSpec, start.since())
+	}
 	if err != nil {
 		return err
 	}
@@ -969,7 +1166,7 @@ func (r *subrunner) runAnalyzers(pkgAct *packageAction, pkg *loader.Package) (an
 		factsOnly:   pkgAct.factsOnly,
 		depObjFacts: depObjFacts,
 		depPkgFacts: depPkgFacts,
-		stats:       &r.Stats,
+		profile:     r.Profile,
 	}
 	queue := make(chan action, len(all))
 	for _, a := range all {
@@ -1082,6 +1279,32 @@ func allAnalyzers(analyzers []*analysis.Analyzer) []*analysis.Analyzer {
 // Run can be called multiple times on the same Runner and it is safe
 // for concurrent use. All runs will share the same semaphore.
 func (r *Runner) Run(cfg *packages.Config, analyzers []*analysis.Analyzer, patterns []string) ([]Result, error) {
+	lpkgs, err := r.Graph(cfg, patterns)
+	if err != nil {
+		return nil, err
+	}
+	return r.RunGraph(lpkgs, analyzers)
+}
+
+// Graph loads the package graph for the packages specified by
+// patterns, for use with RunGraph. cfg is used like in Run.
+func (r *Runner) Graph(cfg *packages.Config, patterns []string) ([]*loader.PackageSpec, error) {
+	r.Stats.setState(StateLoadPackageGraph)
+	t := time.Now()
+	lpkgs, err := loader.Graph(cfg, patterns...)
+	if err != nil {
+		return nil, err
+	}
+	r.Profile.measureGraph(time.Since(t))
+	return lpkgs, nil
+}
+
+// RunGraph is like Run, but analyzes packages of a graph that has
+// already been loaded by Graph. Only lpkgs and their dependencies are
+// analyzed, which need not be all packages of the graph. Together
+// with loader.Refresh, this allows reanalyzing packages after they
+// have changed, without loading the graph again.
+func (r *Runner) RunGraph(lpkgs []*loader.PackageSpec, analyzers []*analysis.Analyzer) ([]Result, error) {
 	analyzers = allAnalyzers(analyzers)
 	registerGobTypes(analyzers)
 
@@ -1094,12 +1317,20 @@ func (r *Runner) Run(cfg *packages.Config, analyzers []*analysis.Analyzer, patte
 		flag.Value.Set(fmt.Sprintf("1.%d", r.GoVersion))
 	}
 
-	r.Stats.setState(StateLoadPackageGraph)
-	lpkgs, err := loader.Graph(cfg, patterns...)
-	if err != nil {
-		return nil, err
+	// Packages excluded by their configuration aren't initial
+	// packages, but they may still be analyzed as dependencies of
+	// other packages.
+	included := make([]*loader.PackageSpec, 0, len(lpkgs))
+	for _, lpkg := range lpkgs {
+		if !lpkg.Config.Excluded(config.Dir(lpkg.GoFiles)) {
+			included = append(included, lpkg)
+		}
 	}
+	lpkgs = included
 	r.Stats.setInitialPackages(len(lpkgs))
+	// MaxMemory may have changed since the last run.
+	r.exports.SetMaxSize(r.memoryCacheSize())
+	r.facts.SetMaxSize(r.memoryCacheSize())
 
 	if len(lpkgs) == 0 {
 		return nil, nil
@@ -1129,6 +1360,9 @@ func (r *Runner) Run(cfg *packages.Config, analyzers []*analysis.Analyzer, patte
 
 	sr := newSubrunner(r, analyzers)
 	for item := range queue {
+		if item != root {
+			r.waitForMemory()
+		}
 		r.semaphore.Acquire()
 		go genericHandle(item, root, queue, &r.semaphore, func(act action) error {
 			return sr.do(act)
@@ -1136,6 +1370,9 @@ func (r *Runner) Run(cfg *packages.Config, analyzers []*analysis.Analyzer, patte
 	}
 
 	r.Stats.setState(StateFinalizing)
+	// Nothing we've cached in memory will be needed again soon.
+	r.exports.Purge()
+	r.facts.Purge()
 	out := make([]Result, 0, len(all))
 	for _, item := range all {
 		if item.Package == nil {
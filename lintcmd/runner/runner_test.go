@@ -0,0 +1,115 @@
+package runner
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"honnef.co/go/tools/config"
+	"honnef.co/go/tools/internal/cache"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/packages"
+)
+
+func TestMaxMemory(t *testing.T) {
+	gopath, err := ioutil.TempDir("", "staticcheck-runner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(gopath)
+	// Independent packages can all be scheduled at once, unless the
+	// memory budget keeps them from being.
+	const n = 6
+	var patterns []string
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("pkg%d", i)
+		dir := filepath.Join(gopath, "src", name)
+		if err := os.MkdirAll(dir, 0777); err != nil {
+			t.Fatal(err)
+		}
+		src := fmt.Sprintf("package %s\n\nfunc Fn() int { return %d }\n", name, i)
+		if err := ioutil.WriteFile(filepath.Join(dir, name+".go"), []byte(src), 0666); err != nil {
+			t.Fatal(err)
+		}
+		patterns = append(patterns, name)
+	}
+	cacheDir := filepath.Join(gopath, "cache")
+	if err := os.Mkdir(cacheDir, 0777); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := New(config.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Analyze every package instead of finding results of earlier
+	// runs in the default cache.
+	if r.cache, err = cache.Open(cacheDir); err != nil {
+		t.Fatal(err)
+	}
+	r.SetWorkers(n)
+	// The heap is always larger than a single byte.
+	r.MaxMemory = 1
+
+	var running, maxRunning int32
+	analyzer := &analysis.Analyzer{
+		Name: "XX9999",
+		Doc:  "records how many packages are analyzed at once",
+		Run: func(*analysis.Pass) (interface{}, error) {
+			m := atomic.AddInt32(&running, 1)
+			for {
+				old := atomic.LoadInt32(&maxRunning)
+				if m <= old || atomic.CompareAndSwapInt32(&maxRunning, old, m) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			return nil, nil
+		},
+	}
+	cfg := &packages.Config{
+		Env: append(os.Environ(), "GOPATH="+gopath, "GO111MODULE=off"),
+	}
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	res, err := r.Run(cfg, []*analysis.Analyzer{analyzer}, patterns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	runtime.ReadMemStats(&after)
+
+	// Scheduling resumes whenever the running package is done, so
+	// all packages get analyzed.
+	if len(res) != n {
+		t.Fatalf("got %d results, want %d", len(res), n)
+	}
+	for _, res := range res {
+		if res.Failed {
+			t.Fatalf("analyzing %s failed: %v", res.Package, res.Errors)
+		}
+	}
+	// Scheduling pauses while another package is running.
+	if maxRunning != 1 {
+		t.Errorf("analyzed up to %d packages at once, want 1", maxRunning)
+	}
+	// Every package but the first one had to wait for another one.
+	if got := r.Stats.Throttles(); got != n-1 {
+		t.Errorf("got %d throttles, want %d", got, n-1)
+	}
+	if r.Stats.Throttled() {
+		t.Error("still throttled after the run")
+	}
+	// Usage never dropped under budget, so a single forced collection
+	// had to do.
+	if got := after.NumForcedGC - before.NumForcedGC; got != 1 {
+		t.Errorf("got %d forced garbage collections, want 1", got)
+	}
+}
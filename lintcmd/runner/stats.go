@@ -2,11 +2,8 @@ package runner
 
 import (
 	"sync/atomic"
-	"time"
 
-	"honnef.co/go/tools/go/loader"
-
-	"golang.org/x/tools/go/analysis"
+	"honnef.co/go/tools/internal/memcache"
 )
 
 const (
@@ -18,14 +15,24 @@ const (
 )
 
 type Stats struct {
+	// heap usage at the time of the last scheduling decision. It
+	// comes first to be 64-bit aligned for atomic access on 32-bit
+	// platforms.
+	heapAlloc uint64
+
 	state                    uint32
 	initialPackages          uint32
 	totalPackages            uint32
 	processedPackages        uint32
 	processedInitialPackages uint32
+	// whether scheduling is paused because of the memory budget
+	throttled uint32
+	// how often scheduling has been paused
+	throttles uint32
 
-	// optional function to call every time an analyzer has finished analyzing a package.
-	PrintAnalyzerMeasurement func(*analysis.Analyzer, *loader.PackageSpec, time.Duration)
+	// the runner's in-memory caches of export data and facts
+	exports *memcache.Cache
+	facts   *memcache.Cache
 }
 
 func (s *Stats) setState(state uint32)    { atomic.StoreUint32(&s.state, state) }
@@ -42,8 +49,32 @@ func (s *Stats) ProcessedInitialPackages() int {
 	return int(atomic.LoadUint32(&s.processedInitialPackages))
 }
 
-func (s *Stats) measureAnalyzer(analysis *analysis.Analyzer, pkg *loader.PackageSpec, d time.Duration) {
-	if s.PrintAnalyzerMeasurement != nil {
-		s.PrintAnalyzerMeasurement(analysis, pkg, d)
+func (s *Stats) throttle() {
+	atomic.StoreUint32(&s.throttled, 1)
+	atomic.AddUint32(&s.throttles, 1)
+}
+func (s *Stats) unthrottle()           { atomic.StoreUint32(&s.throttled, 0) }
+func (s *Stats) setHeapAlloc(n uint64) { atomic.StoreUint64(&s.heapAlloc, n) }
+func (s *Stats) Throttled() bool       { return atomic.LoadUint32(&s.throttled) == 1 }
+func (s *Stats) Throttles() int        { return int(atomic.LoadUint32(&s.throttles)) }
+func (s *Stats) HeapAlloc() uint64     { return atomic.LoadUint64(&s.heapAlloc) }
+
+// MemoryCacheHits returns how often export data and facts were found
+// in the in-memory cache, including data that was being loaded by
+// another package at the time.
+func (s *Stats) MemoryCacheHits() uint64 { return s.exports.Hits() + s.facts.Hits() }
+
+// MemoryCacheMisses returns how often export data and facts had to be
+// loaded from disk.
+func (s *Stats) MemoryCacheMisses() uint64 { return s.exports.Misses() + s.facts.Misses() }
+
+// MemoryCacheHitRate returns the fraction of lookups in the in-memory
+// cache that were hits, or 0 if there were no lookups.
+func (s *Stats) MemoryCacheHitRate() float64 {
+	hits := s.MemoryCacheHits()
+	total := hits + s.MemoryCacheMisses()
+	if total == 0 {
+		return 0
 	}
+	return float64(hits) / float64(total)
 }
@@ -0,0 +1,127 @@
+package lintcmd
+
+// Types for the Static Analysis Results Interchange Format (SARIF),
+// version 2.1.0. Only the subset of the format that we emit is
+// modeled.
+//
+// See https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+
+const (
+	sarifSchema  = "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json"
+	sarifVersion = "2.1.0"
+
+	// sarifSrcRoot is the URI base ID that relative artifact
+	// locations are resolved against. It points at the working
+	// directory staticcheck was run in.
+	sarifSrcRoot = "%SRCROOT%"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool               sarifTool                        `json:"tool"`
+	Invocations        []sarifInvocation                `json:"invocations,omitempty"`
+	OriginalURIBaseIDs map[string]sarifArtifactLocation `json:"originalUriBaseIds,omitempty"`
+	ColumnKind         string                           `json:"columnKind,omitempty"`
+	Results            []sarifResult                    `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Rules          []sarifRule `json:"rules,omitempty"`
+}
+
+type sarifRule struct {
+	ID                   string                      `json:"id"`
+	ShortDescription     sarifMessage                `json:"shortDescription"`
+	FullDescription      *sarifMessage               `json:"fullDescription,omitempty"`
+	HelpURI              string                      `json:"helpUri,omitempty"`
+	DefaultConfiguration sarifReportingConfiguration `json:"defaultConfiguration"`
+	Properties           sarifRuleProperties         `json:"properties"`
+}
+
+type sarifRuleProperties struct {
+	Since      string `json:"since,omitempty"`
+	NonDefault bool   `json:"nonDefault,omitempty"`
+}
+
+type sarifReportingConfiguration struct {
+	Enabled bool   `json:"enabled"`
+	Level   string `json:"level,omitempty"`
+}
+
+type sarifInvocation struct {
+	ExecutionSuccessful bool                  `json:"executionSuccessful"`
+	WorkingDirectory    sarifArtifactLocation `json:"workingDirectory"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID           string             `json:"ruleId"`
+	Level            string             `json:"level"`
+	Message          sarifMessage       `json:"message"`
+	Locations        []sarifLocation    `json:"locations,omitempty"`
+	RelatedLocations []sarifLocation    `json:"relatedLocations,omitempty"`
+	Fixes            []sarifFix         `json:"fixes,omitempty"`
+	Suppressions     []sarifSuppression `json:"suppressions,omitempty"`
+	BaselineState    string             `json:"baselineState,omitempty"`
+}
+
+type sarifLocation struct {
+	ID               int                   `json:"id,omitempty"`
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	Message          *sarifMessage         `json:"message,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
+
+type sarifFix struct {
+	Description     sarifMessage          `json:"description"`
+	ArtifactChanges []sarifArtifactChange `json:"artifactChanges"`
+}
+
+type sarifArtifactChange struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Replacements     []sarifReplacement    `json:"replacements"`
+}
+
+type sarifReplacement struct {
+	DeletedRegion   sarifRegion          `json:"deletedRegion"`
+	InsertedContent sarifArtifactContent `json:"insertedContent"`
+}
+
+type sarifArtifactContent struct {
+	Text string `json:"text"`
+}
+
+type sarifSuppression struct {
+	Kind string `json:"kind"`
+}
@@ -0,0 +1,151 @@
+package lintcmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// This file implements -watch, which keeps analyzing packages as their
+// files change. Packages are analyzed incrementally, the same way as
+// in the language server, and the files of the packages are polled
+// for changes.
+//
+// After every run, the problems are printed as a diff against the
+// previous run. Problems are identified by their baseline
+// fingerprints, so that problems that merely moved aren't reported
+// again.
+
+// watchInterval is how often files are checked for changes.
+const watchInterval = 500 * time.Millisecond
+
+type watcher struct {
+	*incrementalLinter
+	out  io.Writer
+	show func(problem) bool
+
+	// the problems and warnings of the last report
+	problems []fingerprintedProblem
+	warnings map[string]bool
+}
+
+// A fingerprintedProblem is a problem together with its fingerprint,
+// computed when the problem was reported.
+type fingerprintedProblem struct {
+	problem
+	fingerprint baselineEntry
+}
+
+func fingerprintProblems(ps []problem) []fingerprintedProblem {
+	lh := &lineHasher{}
+	out := make([]fingerprintedProblem, len(ps))
+	for i, p := range ps {
+		out[i] = fingerprintedProblem{p, fingerprint(p, lh)}
+	}
+	return out
+}
+
+// diffProblems returns the problems in cur that weren't in old, and
+// the problems in old that aren't in cur anymore.
+func diffProblems(old, cur []fingerprintedProblem) (added, fixed []problem) {
+	subtract := func(a, b []fingerprintedProblem) []problem {
+		counts := map[baselineEntry]int{}
+		for _, p := range b {
+			counts[p.fingerprint]++
+		}
+		var out []problem
+		for _, p := range a {
+			if counts[p.fingerprint] > 0 {
+				counts[p.fingerprint]--
+			} else {
+				out = append(out, p.problem)
+			}
+		}
+		return out
+	}
+	return subtract(cur, old), subtract(old, cur)
+}
+
+// watch analyzes the packages matched by patterns whenever their files
+// change, and writes the problems that were added and fixed by each
+// change to out. Problems for which show returns false aren't
+// reported. watch only returns if the initial analysis fails.
+func watch(l *linter, cfgs []*packages.Config, patterns []string, out io.Writer, show func(problem) bool) error {
+	w := &watcher{
+		incrementalLinter: &incrementalLinter{
+			l:        l,
+			cfgs:     cfgs,
+			patterns: patterns,
+		},
+		out:  out,
+		show: show,
+	}
+	t := time.Now()
+	if err := w.load(); err != nil {
+		return err
+	}
+	if len(w.results()) == 0 {
+		fmt.Fprintf(os.Stderr, "warning: %q matched no packages\n", patterns)
+	}
+	w.report(time.Since(t))
+
+	for {
+		var changed []string
+		var reload bool
+		for len(changed) == 0 && !reload {
+			time.Sleep(watchInterval)
+			changed, reload = w.poll()
+		}
+
+		t := time.Now()
+		if err := w.refresh(changed, reload); err != nil {
+			fmt.Fprintf(w.out, "[%s] %s\n", t.Format("15:04:05"), err)
+			continue
+		}
+		w.report(time.Since(t))
+	}
+}
+
+// report writes the problems that have been added or fixed since the
+// previous report, as well as new warnings.
+func (w *watcher) report(d time.Duration) {
+	now := time.Now().Format("15:04:05")
+	ps, warnings, err := w.l.problems(w.results())
+	if err != nil {
+		fmt.Fprintf(w.out, "[%s] %s\n", now, err)
+		return
+	}
+	shown := ps[:0]
+	for _, p := range ps {
+		if w.show(p) {
+			shown = append(shown, p)
+		}
+	}
+	cur := fingerprintProblems(shown)
+	added, fixed := diffProblems(w.problems, cur)
+	w.problems = cur
+
+	fmt.Fprintf(w.out, "[%s] %d new, %d fixed, %d total (%s)\n",
+		now, len(added), len(fixed), len(cur), d.Round(time.Millisecond))
+	f := textFormatter{W: w.out}
+	for _, p := range added {
+		fmt.Fprint(w.out, "+ ")
+		f.Format(p)
+	}
+	for _, p := range fixed {
+		fmt.Fprint(w.out, "- ")
+		f.Format(p)
+	}
+
+	seen := map[string]bool{}
+	for _, warning := range warnings {
+		seen[warning] = true
+		if !w.warnings[warning] {
+			fmt.Fprintln(os.Stderr, "warning:", warning)
+		}
+	}
+	w.warnings = seen
+}
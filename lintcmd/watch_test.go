@@ -0,0 +1,56 @@
+package lintcmd
+
+import (
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"honnef.co/go/tools/lintcmd/runner"
+)
+
+func TestDiffProblems(t *testing.T) {
+	dir, err := ioutil.TempDir("", "staticcheck")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "pkg.go")
+	write := func(src string) {
+		if err := ioutil.WriteFile(file, []byte(src), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	prob := func(check string, line int) problem {
+		return problem{
+			Diagnostic: runner.Diagnostic{
+				Position: token.Position{Filename: file, Line: line, Column: 1},
+				Category: check,
+				Message:  "message",
+			},
+			Package: "example.com/pkg",
+		}
+	}
+
+	write("package pkg\n\nvar x = 1\nvar y = 2\n")
+	old := fingerprintProblems([]problem{prob("SA1000", 3), prob("SA1001", 4)})
+
+	// Line 3 moves to line 4, line 4 is removed, and a new problem
+	// occurs on the new line 3.
+	write("package pkg\n\nvar z = 3\nvar x = 1\n")
+	cur := fingerprintProblems([]problem{prob("SA1002", 3), prob("SA1000", 4)})
+
+	added, fixed := diffProblems(old, cur)
+	if len(added) != 1 || added[0].Category != "SA1002" {
+		t.Errorf("got added problems %v, want SA1002", added)
+	}
+	if len(fixed) != 1 || fixed[0].Category != "SA1001" || fixed[0].Position.Line != 4 {
+		t.Errorf("got fixed problems %v, want SA1001 on line 4", fixed)
+	}
+
+	added, fixed = diffProblems(nil, cur)
+	if len(added) != 2 || len(fixed) != 0 {
+		t.Errorf("first run: got %d added and %d fixed problems, want 2 and 0", len(added), len(fixed))
+	}
+}
@@ -6,96 +6,79 @@ var Docs = map[string]*lint.Documentation{
 	"S1000": {
 		Title: `Use plain channel send or receive instead of single-case select`,
 		Text: `Select statements with a single case can be replaced with a simple
-send or receive.
-
-Before:
-
-    select {
-    case x := <-ch:
-        fmt.Println(x)
-    }
-
-After:
-
-    x := <-ch
-    fmt.Println(x)`,
-		Since: "2017.1",
+send or receive.`,
+		Before: `select {
+case x := <-ch:
+    fmt.Println(x)
+}`,
+		After: `x := <-ch
+fmt.Println(x)`,
+		Related:           []string{"S1037"},
+		SeverityRationale: `A single-case select behaves exactly like the plain channel operation; the select only adds indentation and hides the blocking operation.`,
+		Since:             "2017.1",
 	},
 
 	"S1001": {
 		Title: `Replace for loop with call to copy`,
-		Text: `Use copy() for copying elements from one slice to another.
-
-Before:
-
-    for i, x := range src {
-        dst[i] = x
-    }
-
-After:
-
-    copy(dst, src)`,
-		Since: "2017.1",
+		Text:  `Use copy() for copying elements from one slice to another.`,
+		Before: `for i, x := range src {
+    dst[i] = x
+}`,
+		After:             `copy(dst, src)`,
+		Related:           []string{"S1011", "S1018"},
+		SeverityRationale: `The loop is correct, but copy states the intent directly and is typically faster.`,
+		Since:             "2017.1",
 	},
 
 	"S1002": {
-		Title: `Omit comparison with boolean constant`,
-		Text: `Before:
-
-    if x == true {}
-
-After:
-
-    if x {}`,
-		Since: "2017.1",
+		Title:             `Omit comparison with boolean constant`,
+		Before:            `if x == true {}`,
+		After:             `if x {}`,
+		SeverityRationale: `Comparing with true or false is redundant and makes conditions longer without changing their meaning.`,
+		Since:             "2017.1",
 	},
 
 	"S1003": {
-		Title: `Replace call to strings.Index with strings.Contains`,
-		Text: `Before:
-
-    if strings.Index(x, y) != -1 {}
-
-After:
-
-    if strings.Contains(x, y) {}`,
-		Since: "2017.1",
+		Title:             `Replace call to strings.Index with strings.Contains`,
+		Before:            `if strings.Index(x, y) != -1 {}`,
+		After:             `if strings.Contains(x, y) {}`,
+		Related:           []string{"S1017"},
+		SeverityRationale: `strings.Index compared with -1 works, but strings.Contains says what the code means.`,
+		Since:             "2017.1",
 	},
 
 	"S1004": {
-		Title: `Replace call to bytes.Compare with bytes.Equal`,
-		Text: `Before:
-
-    if bytes.Compare(x, y) == 0 {}
-
-After:
-
-    if bytes.Equal(x, y) {}`,
-		Since: "2017.1",
+		Title:             `Replace call to bytes.Compare with bytes.Equal`,
+		Before:            `if bytes.Compare(x, y) == 0 {}`,
+		After:             `if bytes.Equal(x, y) {}`,
+		SeverityRationale: `bytes.Compare returns the same answer, but bytes.Equal is clearer and can be faster, since it doesn't have to determine the ordering.`,
+		Since:             "2017.1",
 	},
 
 	"S1005": {
 		Title: `Drop unnecessary use of the blank identifier`,
-		Text: `In many cases, assigning to the blank identifier is unnecessary.
-
-Before:
-
-    for _ = range s {}
-    x, _ = someMap[key]
-    _ = <-ch
-
-After:
-
-    for range s{}
-    x = someMap[key]
-    <-ch`,
-		Since: "2017.1",
+		Text:  `In many cases, assigning to the blank identifier is unnecessary.`,
+		Before: `for _ = range s {}
+x, _ = someMap[key]
+_ = <-ch`,
+		After: `for range s{}
+x = someMap[key]
+<-ch`,
+		SeverityRationale: `Assignments to the blank identifier that the language doesn't require are noise for readers and have no effect.`,
+		Since:             "2017.1",
 	},
 
 	"S1006": {
 		Title: `Use for { ... } for infinite loops`,
 		Text:  `For infinite loops, using for { ... } is the most idiomatic choice.`,
-		Since: "2017.1",
+		Before: `for true {
+    ...
+}`,
+		After: `for {
+    ...
+}`,
+		SeverityRationale: `for true works, but for { ... } is the idiomatic spelling of an infinite loop and is what readers expect.`,
+		Since:             "2017.1",
 	},
 
 	"S1007": {
@@ -105,83 +88,66 @@ any escape sequences. This means that the backslash (\) can be used
 freely, without the need of escaping.
 
 Since regular expressions have their own escape sequences, raw strings
-can improve their readability.
-
-Before:
-
-    regexp.Compile("\\A(\\w+) profile: total \\d+\\n\\z")
-
-After:
-
-    regexp.Compile(` + "`" + `\A(\w+) profile: total \d+\n\z` + "`" + `)`,
-		Since: "2017.1",
+can improve their readability.`,
+		Before:            `regexp.Compile("\\A(\\w+) profile: total \\d+\\n\\z")`,
+		After:             `regexp.Compile(` + "`" + `\A(\w+) profile: total \d+\n\z` + "`" + `)`,
+		SeverityRationale: `Doubled backslashes make regular expressions harder to read and to get right; the behavior is unchanged.`,
+		Since:             "2017.1",
 	},
 
 	"S1008": {
 		Title: `Simplify returning boolean expression`,
-		Text: `Before:
-
-    if <expr> {
-        return true
-    }
-    return false
-
-After:
-
-    return <expr>`,
-		Since: "2017.1",
+		Before: `if <expr> {
+    return true
+}
+return false`,
+		After:             `return <expr>`,
+		SeverityRationale: `Returning a boolean through an if statement adds lines without adding information.`,
+		Since:             "2017.1",
 	},
 
 	"S1009": {
 		Title: `Omit redundant nil check on slices`,
 		Text: `The len function is defined for all slices, even nil ones, which have
 a length of zero. It is not necessary to check if a slice is not nil
-before checking that its length is not zero.
-
-Before:
-
-    if x != nil && len(x) != 0 {}
-
-After:
-
-    if len(x) != 0 {}`,
-		Since: "2017.1",
+before checking that its length is not zero.`,
+		Before:            `if x != nil && len(x) != 0 {}`,
+		After:             `if len(x) != 0 {}`,
+		Related:           []string{"S1031"},
+		SeverityRationale: `The nil check is redundant because len of a nil slice is zero; removing it doesn't change behavior.`,
+		Since:             "2017.1",
 	},
 
 	"S1010": {
 		Title: `Omit default slice index`,
 		Text: `When slicing, the second index defaults to the length of the value,
 making s[n:len(s)] and s[n:] equivalent.`,
-		Since: "2017.1",
+		Before:            `x := s[n:len(s)]`,
+		After:             `x := s[n:]`,
+		SeverityRationale: `Spelling out the default index is redundant and slightly harder to read than omitting it.`,
+		Since:             "2017.1",
 	},
 
 	"S1011": {
 		Title: `Use a single append to concatenate two slices`,
-		Text: `Before:
-
-    for _, e := range y {
-        x = append(x, e)
-    }
-
-After:
-
-    x = append(x, y...)`,
-		Since: "2017.1",
+		Before: `for _, e := range y {
+    x = append(x, e)
+}`,
+		After:             `x = append(x, y...)`,
+		Related:           []string{"S1001"},
+		SeverityRationale: `Appending element by element is correct but verbose, and may grow the slice several times instead of once.`,
+		Since:             "2017.1",
 	},
 
 	"S1012": {
 		Title: `Replace time.Now().Sub(x) with time.Since(x)`,
 		Text: `The time.Since helper has the same effect as using time.Now().Sub(x)
-but is easier to read.
-
-Before:
-
-    time.Now().Sub(x)
-
-After:
-
-    time.Since(x)`,
-		Since: "2017.1",
+but is easier to read.`,
+		Before:            `time.Now().Sub(x)`,
+		After:             `time.Since(x)`,
+		Related:           []string{"S1024"},
+		SeverityRationale: `time.Since is equivalent and shorter; this is purely a readability improvement.`,
+		Since:             "2017.1",
 	},
 
 	"S1016": {
@@ -190,21 +156,16 @@ After:
 other. In older versions of Go, the fields had to have identical
 struct tags. Since Go 1.8, however, struct tags are ignored during
 conversions. It is thus not necessary to manually copy every field
-individually.
-
-Before:
-
-    var x T1
-    y := T2{
-        Field1: x.Field1,
-        Field2: x.Field2,
-    }
-
-After:
-
-    var x T1
-    y := T2(x)`,
-		Since: "2017.1",
+individually.`,
+		Before: `var x T1
+y := T2{
+    Field1: x.Field1,
+    Field2: x.Field2,
+}`,
+		After: `var x T1
+y := T2(x)`,
+		SeverityRationale: `Copying fields by hand is correct as long as it is kept complete, but it silently misses fields that are added to both types later.`,
+		Since:             "2017.1",
 	},
 
 	"S1017": {
@@ -213,36 +174,28 @@ After:
 strings.TrimPrefix function. If the string doesn't start with the
 prefix, the original string will be returned. Using strings.TrimPrefix
 reduces complexity, and avoids common bugs, such as off-by-one
-mistakes.
-
-Before:
-
-    if strings.HasPrefix(str, prefix) {
-        str = str[len(prefix):]
-    }
-
-After:
-
-    str = strings.TrimPrefix(str, prefix)`,
-		Since: "2017.1",
+mistakes.`,
+		Before: `if strings.HasPrefix(str, prefix) {
+    str = str[len(prefix):]
+}`,
+		After:             `str = strings.TrimPrefix(str, prefix)`,
+		Related:           []string{"S1003"},
+		SeverityRationale: `Manual trimming works, but it is easy to get the slicing wrong, and strings.TrimPrefix is shorter.`,
+		Since:             "2017.1",
 	},
 
 	"S1018": {
 		Title: `Use copy for sliding elements`,
 		Text: `copy() permits using the same source and destination slice, even with
 overlapping ranges. This makes it ideal for sliding elements in a
-slice.
-
-Before:
-
-    for i := 0; i < n; i++ {
-        bs[i] = bs[offset+i]
-    }
-
-After:
-
-    copy(bs[:n], bs[offset:])`,
-		Since: "2017.1",
+slice.`,
+		Before: `for i := 0; i < n; i++ {
+    bs[i] = bs[offset+i]
+}`,
+		After:             `copy(bs[:n], bs[offset:])`,
+		Related:           []string{"S1001"},
+		SeverityRationale: `The loop is correct, but copy is shorter, handles overlapping ranges and is typically faster.`,
+		Since:             "2017.1",
 	},
 
 	"S1019": {
@@ -250,32 +203,29 @@ After:
 		Text: `The make function has default values for the length and capacity
 arguments. For channels and maps, the length defaults to zero.
 Additionally, for slices the capacity defaults to the length.`,
-		Since: "2017.1",
+		Before: `make(map[string]int, 0)
+make([]int, 10, 10)`,
+		After: `make(map[string]int)
+make([]int, 10)`,
+		SeverityRationale: `The extra arguments repeat the defaults of make and have no effect.`,
+		Since:             "2017.1",
 	},
 
 	"S1020": {
-		Title: `Omit redundant nil check in type assertion`,
-		Text: `Before:
-
-    if _, ok := i.(T); ok && i != nil {}
-
-After:
-
-    if _, ok := i.(T); ok {}`,
-		Since: "2017.1",
+		Title:             `Omit redundant nil check in type assertion`,
+		Before:            `if _, ok := i.(T); ok && i != nil {}`,
+		After:             `if _, ok := i.(T); ok {}`,
+		SeverityRationale: `A successful type assertion already implies a non-nil interface, so the extra check never changes the outcome.`,
+		Since:             "2017.1",
 	},
 
 	"S1021": {
 		Title: `Merge variable declaration and assignment`,
-		Text: `Before:
-
-    var x uint
-    x = 1
-
-After:
-
-    var x uint = 1`,
-		Since: "2017.1",
+		Before: `var x uint
+x = 1`,
+		After:             `var x uint = 1`,
+		SeverityRationale: `Separating the declaration from its first assignment adds a line and leaves a window in which the variable has its zero value.`,
+		Since:             "2017.1",
 	},
 
 	"S1023": {
@@ -286,22 +236,26 @@ the final statement of the function.
 Switches in Go do not have automatic fallthrough, unlike languages
 like C. It is not necessary to have a break statement as the final
 statement in a case block.`,
-		Since: "2017.1",
+		Before: `func fn() {
+    doSomething()
+    return
+}`,
+		After: `func fn() {
+    doSomething()
+}`,
+		SeverityRationale: `Redundant return and break statements have no effect and may suggest to readers that they do.`,
+		Since:             "2017.1",
 	},
 
 	"S1024": {
 		Title: `Replace x.Sub(time.Now()) with time.Until(x)`,
 		Text: `The time.Until helper has the same effect as using x.Sub(time.Now())
-but is easier to read.
-
-Before:
-
-    x.Sub(time.Now())
-
-After:
-
-    time.Until(x)`,
-		Since: "2017.1",
+but is easier to read.`,
+		Before:            `x.Sub(time.Now())`,
+		After:             `time.Until(x)`,
+		Related:           []string{"S1012"},
+		SeverityRationale: `time.Until is equivalent and shorter; this is purely a readability improvement.`,
+		Since:             "2017.1",
 	},
 
 	"S1025": {
@@ -333,19 +287,18 @@ to
     x
     string(y)
     z.String()`,
-		Since: "2017.1",
+		Related:           []string{"S1039"},
+		SeverityRationale: `Formatting a value that already is a string, or that has a String method, wastes work; the result is the same.`,
+		Since:             "2017.1",
 	},
 
 	"S1028": {
-		Title: `Simplify error construction with fmt.Errorf`,
-		Text: `Before:
-
-    errors.New(fmt.Sprintf(...))
-
-After:
-
-    fmt.Errorf(...)`,
-		Since: "2017.1",
+		Title:             `Simplify error construction with fmt.Errorf`,
+		Before:            `errors.New(fmt.Sprintf(...))`,
+		After:             `fmt.Errorf(...)`,
+		Related:           []string{"S1038"},
+		SeverityRationale: `Building the message with fmt.Sprintf and then wrapping it with errors.New is equivalent to a single, shorter call to fmt.Errorf.`,
+		Since:             "2017.1",
 	},
 
 	"S1029": {
@@ -354,16 +307,12 @@ After:
 isn't used, this is functionally equivalent to converting the string
 to a slice of runes and ranging over that. Ranging directly over the
 string will be more performant, however, as it avoids allocating a new
-slice, the size of which depends on the length of the string.
-
-Before:
-
-    for _, r := range []rune(s) {}
-
-After:
-
-    for _, r := range s {}`,
-		Since: "2017.1",
+slice, the size of which depends on the length of the string.`,
+		Before:            `for _, r := range []rune(s) {}`,
+		After:             `for _, r := range s {}`,
+		Related:           []string{"SA6003"},
+		SeverityRationale: `Converting the string to a slice of runes allocates memory that ranging over the string avoids; the loop behaves the same.`,
+		Since:             "2017.1",
 	},
 
 	"S1030": {
@@ -375,63 +324,77 @@ use the other method.
 The only exception to this are map lookups. Due to a compiler optimization,
 m[string(buf.Bytes())] is more efficient than m[buf.String()].
 `,
-		Since: "2017.1",
+		Before:            `string(buf.Bytes())`,
+		After:             `buf.String()`,
+		Related:           []string{"SA6001"},
+		SeverityRationale: `The conversion copies the buffer's contents a second time, which only costs performance, not correctness.`,
+		Since:             "2017.1",
 	},
 
 	"S1031": {
 		Title: `Omit redundant nil check around loop`,
 		Text: `You can use range on nil slices and maps, the loop will simply never
 execute. This makes an additional nil check around the loop
-unnecessary.
-
-Before:
-
-    if s != nil {
-        for _, x := range s {
-            ...
-        }
-    }
-
-After:
-
+unnecessary.`,
+		Before: `if s != nil {
     for _, x := range s {
         ...
-    }`,
-		Since: "2017.1",
+    }
+}`,
+		After: `for _, x := range s {
+    ...
+}`,
+		Related:           []string{"S1009"},
+		SeverityRationale: `Ranging over a nil slice or map does nothing, so the surrounding check only adds nesting.`,
+		Since:             "2017.1",
 	},
 
 	"S1032": {
 		Title: `Use sort.Ints(x), sort.Float64s(x), and sort.Strings(x)`,
 		Text: `The sort.Ints, sort.Float64s and sort.Strings functions are easier to
 read than sort.Sort(sort.IntSlice(x)), sort.Sort(sort.Float64Slice(x))
-and sort.Sort(sort.StringSlice(x)).
-
-Before:
-
-    sort.Sort(sort.StringSlice(x))
-
-After:
-
-    sort.Strings(x)`,
-		Since: "2019.1",
+and sort.Sort(sort.StringSlice(x)).`,
+		Before:            `sort.Sort(sort.StringSlice(x))`,
+		After:             `sort.Strings(x)`,
+		SeverityRationale: `The convenience functions behave identically and are easier to read than the explicit conversion.`,
+		Since:             "2019.1",
 	},
 
 	"S1033": {
 		Title: `Unnecessary guard around call to delete`,
 		Text:  `Calling delete on a nil map is a no-op.`,
-		Since: "2019.2",
+		Before: `if _, ok := m[k]; ok {
+    delete(m, k)
+}`,
+		After:             `delete(m, k)`,
+		Related:           []string{"S1036"},
+		SeverityRationale: `delete is a no-op for missing keys, so the guard only adds a second map lookup.`,
+		Since:             "2019.2",
 	},
 
 	"S1034": {
 		Title: `Use result of type assertion to simplify cases`,
-		Since: "2019.2",
+		Before: `switch x.(type) {
+case int:
+    fmt.Println(x.(int) + 1)
+}`,
+		After: `switch x := x.(type) {
+case int:
+    fmt.Println(x + 1)
+}`,
+		SeverityRationale: `Repeating the type assertion in every case is redundant and makes the cases harder to read.`,
+		Since:             "2019.2",
 	},
 
 	"S1035": {
 		Title: `Redundant call to net/http.CanonicalHeaderKey in method call on net/http.Header`,
 		Text: `The methods on net/http.Header, namely Add, Del, Get and Set, already
 canonicalize the given header name.`,
-		Since: "2020.1",
+		Before:            `h.Set(http.CanonicalHeaderKey("etag"), "1234")`,
+		After:             `h.Set("etag", "1234")`,
+		Related:           []string{"SA1008"},
+		SeverityRationale: `The header methods already canonicalize keys, so the explicit call only costs a little performance.`,
+		Since:             "2020.1",
 	},
 
 	"S1036": {
@@ -464,7 +427,9 @@ can be simplified to
 
     m["k"] += 4
 `,
-		Since: "2020.1",
+		Related:           []string{"S1033"},
+		SeverityRationale: `The zero value of the missing key already gives the right result, so the extra lookup and branch are redundant.`,
+		Since:             "2020.1",
 	},
 
 	"S1037": {
@@ -472,18 +437,32 @@ can be simplified to
 		Text: `Using a select statement with a single case receiving
 from the result of time.After is a very elaborate way of sleeping that
 can much simpler be expressed with a simple call to time.Sleep.`,
-		Since: "2020.1",
+		Before: `select {
+case <-time.After(time.Second):
+}`,
+		After:             `time.Sleep(time.Second)`,
+		Related:           []string{"S1000"},
+		SeverityRationale: `The select statement is an obscure way of sleeping; time.Sleep behaves the same and is immediately recognizable.`,
+		Since:             "2020.1",
 	},
 
 	"S1038": {
-		Title: "Unnecessarily complex way of printing formatted string",
-		Text:  `Instead of using fmt.Print(fmt.Sprintf(...)), one can use fmt.Printf(...).`,
-		Since: "2020.1",
+		Title:             "Unnecessarily complex way of printing formatted string",
+		Text:              `Instead of using fmt.Print(fmt.Sprintf(...)), one can use fmt.Printf(...).`,
+		Before:            `fmt.Print(fmt.Sprintf("%d items", n))`,
+		After:             `fmt.Printf("%d items", n)`,
+		Related:           []string{"S1028"},
+		SeverityRationale: `Formatting into a string first and then printing it is equivalent to a single Printf call, but allocates an intermediate string.`,
+		Since:             "2020.1",
 	},
 
 	"S1039": {
-		Title: "Unnecessary use of fmt.Sprint",
-		Text:  `Calling fmt.Sprint with a single string argument is unnecessary and identical to using the string directly.`,
-		Since: "2020.1",
+		Title:             "Unnecessary use of fmt.Sprint",
+		Text:              `Calling fmt.Sprint with a single string argument is unnecessary and identical to using the string directly.`,
+		Before:            `fmt.Sprint("hello")`,
+		After:             `"hello"`,
+		Related:           []string{"S1025"},
+		SeverityRationale: `fmt.Sprint with a single string argument returns that string, so the call only costs performance.`,
+		Since:             "2020.1",
 	},
 }
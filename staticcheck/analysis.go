@@ -3,6 +3,7 @@ package staticcheck
 import (
 	"honnef.co/go/tools/analysis/facts"
 	"honnef.co/go/tools/analysis/lint"
+	"honnef.co/go/tools/config"
 	"honnef.co/go/tools/internal/passes/buildir"
 
 	"golang.org/x/tools/go/analysis"
@@ -66,7 +67,7 @@ var Analyzers = lint.InitializeAnalyzers(Docs, map[string]*analysis.Analyzer{
 	"SA1018": makeCallCheckerAnalyzer(checkStringsReplaceZeroRules),
 	"SA1019": {
 		Run:      CheckDeprecated,
-		Requires: []*analysis.Analyzer{inspect.Analyzer, facts.Deprecated, facts.Generated},
+		Requires: []*analysis.Analyzer{inspect.Analyzer, facts.Deprecated, facts.Generated, config.Analyzer},
 	},
 	"SA1020": makeCallCheckerAnalyzer(checkListenAddressRules),
 	"SA1021": makeCallCheckerAnalyzer(checkBytesEqualIPRules),
@@ -165,7 +166,7 @@ var Analyzers = lint.InitializeAnalyzers(Docs, map[string]*analysis.Analyzer{
 	},
 	"SA4017": {
 		Run:      CheckPureFunctions,
-		Requires: []*analysis.Analyzer{buildir.Analyzer, facts.Purity},
+		Requires: []*analysis.Analyzer{buildir.Analyzer, facts.Purity, config.Analyzer},
 	},
 	"SA4018": {
 		Run:      CheckSelfAssignment,
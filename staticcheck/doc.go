@@ -4,18 +4,26 @@ import "honnef.co/go/tools/analysis/lint"
 
 var Docs = map[string]*lint.Documentation{
 	"SA1000": {
-		Title: `Invalid regular expression`,
-		Since: "2017.1",
+		Title:             `Invalid regular expression`,
+		Before:            `regexp.MustCompile(` + "`" + `foo(bar` + "`" + `)`,
+		After:             `regexp.MustCompile(` + "`" + `foo\(bar` + "`" + `)`,
+		SeverityRationale: `Invalid regular expressions cause regexp.Compile to return an error and regexp.MustCompile to panic at run time.`,
+		Related:           []string{"SA6000"},
+		Since:             "2017.1",
 	},
 
 	"SA1001": {
-		Title: `Invalid template`,
-		Since: "2017.1",
+		Title:             `Invalid template`,
+		SeverityRationale: `Parsing an invalid template fails at run time, and template.Must turns that failure into a panic.`,
+		Since:             "2017.1",
 	},
 
 	"SA1002": {
-		Title: `Invalid format in time.Parse`,
-		Since: "2017.1",
+		Title:             `Invalid format in time.Parse`,
+		Before:            `time.Parse("02/01/2006 13:04", s)`,
+		After:             `time.Parse("02/01/2006 15:04", s)`,
+		SeverityRationale: `A layout that doesn't use Go's reference time either fails to parse valid input or silently produces the wrong time.`,
+		Since:             "2017.1",
 	},
 
 	"SA1003": {
@@ -26,7 +34,8 @@ differ on different architectures. Furthermore, it doesn't support
 serializing maps, channels, strings, or functions.
 
 Before Go 1.8, bool wasn't supported, either.`,
-		Since: "2017.1",
+		SeverityRationale: `binary.Write and binary.Read return an error for values without a fixed size, so the data is never written or read.`,
+		Since:             "2017.1",
 	},
 
 	"SA1004": {
@@ -43,7 +52,10 @@ arbitrary durations, for example '5 * time.Second' for 5 seconds.
 If you truly meant to sleep for a tiny amount of time, use
 'n * time.Nanosecond' to signal to Staticcheck that you did mean to sleep
 for some amount of nanoseconds.`,
-		Since: "2017.1",
+		Before:            `time.Sleep(1)`,
+		After:             `time.Sleep(1 * time.Second)`,
+		SeverityRationale: `Sleeping for a few nanoseconds instead of seconds usually turns retry loops into busy loops and breaks timing assumptions.`,
+		Since:             "2017.1",
 	},
 
 	"SA1005": {
@@ -66,7 +78,10 @@ the following – but be aware that not all systems, particularly
 Windows, will have a /bin/sh program:
 
     exec.Command("/bin/sh", "-c", "ls | grep Awesome")`,
-		Since: "2017.1",
+		Before:            `exec.Command("ls /tmp")`,
+		After:             `exec.Command("ls", "/tmp")`,
+		SeverityRationale: `exec.Command doesn't use a shell, so a first argument containing a whole command line names a program that doesn't exist.`,
+		Since:             "2017.1",
 	},
 
 	"SA1006": {
@@ -90,12 +105,17 @@ Similarly, forming the first parameter via string concatenation with
 user input should be avoided for the same reason. When printing user
 input, either use a variant of fmt.Print, or use the %s Printf verb
 and pass the string as an argument.`,
-		Since: "2017.1",
+		Before:            `fmt.Printf(s)`,
+		After:             `fmt.Print(s)`,
+		Related:           []string{"SA5009"},
+		SeverityRationale: `Any percent sign in the dynamic string is interpreted as a formatting verb, which garbles the output.`,
+		Since:             "2017.1",
 	},
 
 	"SA1007": {
-		Title: `Invalid URL in net/url.Parse`,
-		Since: "2017.1",
+		Title:             `Invalid URL in net/url.Parse`,
+		SeverityRationale: `url.Parse returns an error for the invalid URL at run time.`,
+		Since:             "2017.1",
 	},
 
 	"SA1008": {
@@ -120,39 +140,80 @@ demonstrates one such inconsistency:
 
 The easiest way of obtaining the canonical form of a key is to use
 http.CanonicalHeaderKey.`,
-		Since: "2017.1",
+		Before:            `h["etag"] = []string{"1234"}`,
+		After:             `h["Etag"] = []string{"1234"}`,
+		Related:           []string{"S1035"},
+		SeverityRationale: `Direct map accesses bypass canonicalization, so lookups don't find values that were set through the Header methods, and vice versa.`,
+		Since:             "2017.1",
 	},
 
 	"SA1010": {
 		Title: `(*regexp.Regexp).FindAll called with n == 0, which will always return zero results`,
 		Text: `If n >= 0, the function returns at most n matches/submatches. To
 return all results, specify a negative number.`,
-		Since: "2017.1",
+		Before:            `re.FindAllString(s, 0)`,
+		After:             `re.FindAllString(s, -1)`,
+		Related:           []string{"SA1018"},
+		SeverityRationale: `With n == 0 the call always returns nil, which is never what the caller wanted.`,
+		Since:             "2017.1",
 	},
 
 	"SA1011": {
-		Title: `Various methods in the strings package expect valid UTF-8, but invalid input is provided`,
-		Since: "2017.1",
+		Title:             `Various methods in the strings package expect valid UTF-8, but invalid input is provided`,
+		SeverityRationale: `Functions that expect valid UTF-8 produce surprising results for invalid input, for example by treating bytes as replacement characters.`,
+		Since:             "2017.1",
 	},
 
 	"SA1012": {
-		Title: `A nil context.Context is being passed to a function, consider using context.TODO instead`,
-		Since: "2017.1",
+		Title:             `A nil context.Context is being passed to a function, consider using context.TODO instead`,
+		Before:            `req, err := http.NewRequestWithContext(nil, "GET", url, nil)`,
+		After:             `req, err := http.NewRequestWithContext(context.TODO(), "GET", url, nil)`,
+		SeverityRationale: `Most functions accepting a context.Context call methods on it and panic when given nil.`,
+		Related:           []string{"SA1029"},
+		Since:             "2017.1",
 	},
 
 	"SA1013": {
-		Title: `io.Seeker.Seek is being called with the whence constant as the first argument, but it should be the second`,
-		Since: "2017.1",
+		Title:             `io.Seeker.Seek is being called with the whence constant as the first argument, but it should be the second`,
+		Before:            `f.Seek(io.SeekStart, 0)`,
+		After:             `f.Seek(0, io.SeekStart)`,
+		SeverityRationale: `The arguments are swapped, so the call seeks to a tiny offset, relative to the wrong position.`,
+		Since:             "2017.1",
 	},
 
 	"SA1014": {
 		Title: `Non-pointer value passed to Unmarshal or Decode`,
-		Since: "2017.1",
+		Before: `var v T
+json.Unmarshal(data, v)`,
+		After: `var v T
+json.Unmarshal(data, &v)`,
+		SeverityRationale: `Unmarshal returns an error for non-pointer values, and the value is never populated.`,
+		Related:           []string{"SA1026", "SA9005"},
+		Since:             "2017.1",
 	},
 
 	"SA1015": {
 		Title: `Using time.Tick in a way that will leak. Consider using time.NewTicker, and only use time.Tick in tests, commands and endless functions`,
-		Since: "2017.1",
+		Before: `for {
+    select {
+    case <-time.Tick(time.Second):
+        poll()
+    case <-done:
+        return
+    }
+}`,
+		After: `t := time.NewTicker(time.Second)
+defer t.Stop()
+for {
+    select {
+    case <-t.C:
+        poll()
+    case <-done:
+        return
+    }
+}`,
+		SeverityRationale: `Tickers created by time.Tick can never be stopped and leak for the rest of the program's lifetime.`,
+		Since:             "2017.1",
 	},
 
 	"SA1016": {
@@ -161,7 +222,9 @@ return all results, specify a negative number.`,
 UNIX-like systems, the syscall.SIGKILL and syscall.SIGSTOP signals are
 never passed to the process, but instead handled directly by the
 kernel. It is therefore pointless to try and handle these signals.`,
-		Since: "2017.1",
+		Related:           []string{"SA1017"},
+		SeverityRationale: `The program is killed or stopped regardless, so the signal handling code never runs.`,
+		Since:             "2017.1",
 	},
 
 	"SA1017": {
@@ -172,24 +235,39 @@ channel is either unbuffered or full, the signal will be dropped. To
 avoid missing signals, the channel should be buffered and of the
 appropriate size. For a channel used for notification of just one
 signal value, a buffer of size 1 is sufficient.`,
-		Since: "2017.1",
+		Before: `c := make(chan os.Signal)
+signal.Notify(c, os.Interrupt)`,
+		After: `c := make(chan os.Signal, 1)
+signal.Notify(c, os.Interrupt)`,
+		SeverityRationale: `Signals that arrive while nobody is receiving from an unbuffered channel are silently dropped.`,
+		Related:           []string{"SA1016"},
+		Since:             "2017.1",
 	},
 
 	"SA1018": {
 		Title: `strings.Replace called with n == 0, which does nothing`,
 		Text: `With n == 0, zero instances will be replaced. To replace all
 instances, use a negative number, or use strings.ReplaceAll.`,
-		Since: "2017.1",
+		Before:            `strings.Replace(s, "a", "b", 0)`,
+		After:             `strings.ReplaceAll(s, "a", "b")`,
+		Related:           []string{"SA1010"},
+		SeverityRationale: `With n == 0 the call returns its input unchanged, which is never what the caller wanted.`,
+		Since:             "2017.1",
 	},
 
 	"SA1019": {
-		Title: `Using a deprecated function, variable, constant or field`,
-		Since: "2017.1",
+		Title:             `Using a deprecated function, variable, constant or field`,
+		SeverityRationale: `Deprecated APIs usually still work, but they may be removed in the future, or have better alternatives that avoid known problems.`,
+		Since:             "2017.1",
+		CheckOptions: &DeprecatedOptions{
+			ExtraDeprecated: map[string]string{},
+		},
 	},
 
 	"SA1020": {
-		Title: `Using an invalid host:port pair with a net.Listen-related function`,
-		Since: "2017.1",
+		Title:             `Using an invalid host:port pair with a net.Listen-related function`,
+		SeverityRationale: `Listening fails at run time with an error for the invalid address.`,
+		Since:             "2017.1",
 	},
 
 	"SA1021": {
@@ -199,13 +277,17 @@ length of the slice for an IPv4 address, however, can be either 4 or
 16 bytes long, using different ways of representing IPv4 addresses. In
 order to correctly compare two net.IPs, the net.IP.Equal method should
 be used, as it takes both representations into account.`,
-		Since: "2017.1",
+		Before:            `if bytes.Equal(ip1, ip2) {}`,
+		After:             `if ip1.Equal(ip2) {}`,
+		SeverityRationale: `The same IP address has several byte representations, so bytes.Equal may report that equal addresses differ.`,
+		Since:             "2017.1",
 	},
 
 	"SA1023": {
-		Title: `Modifying the buffer in an io.Writer implementation`,
-		Text:  `Write must not modify the slice data, even temporarily.`,
-		Since: "2017.1",
+		Title:             `Modifying the buffer in an io.Writer implementation`,
+		Text:              `Write must not modify the slice data, even temporarily.`,
+		SeverityRationale: `Callers are free to reuse the buffer after Write returns, and modifying it corrupts their data.`,
+		Since:             "2017.1",
 	},
 
 	"SA1024": {
@@ -220,17 +302,23 @@ will result in the string "word" – any characters that are 1, 2, 3 or
 4 are cut from the left of the string.
 
 In order to remove one string from another, use strings.TrimPrefix instead.`,
-		Since: "2017.1",
+		Before:            `strings.TrimLeft(s, "http://")`,
+		After:             `strings.TrimPrefix(s, "http://")`,
+		SeverityRationale: `Duplicate characters suggest that the function was mistaken for one that trims a prefix or suffix, which causes it to remove more than intended.`,
+		Since:             "2017.1",
 	},
 
 	"SA1025": {
-		Title: `It is not possible to use (*time.Timer).Reset's return value correctly`,
-		Since: "2019.1",
+		Title:             `It is not possible to use (*time.Timer).Reset's return value correctly`,
+		SeverityRationale: `Relying on the return value leads to races with the timer's channel, which cause timers to fire twice or not at all.`,
+		Since:             "2019.1",
 	},
 
 	"SA1026": {
-		Title: `Cannot marshal channels or functions`,
-		Since: "2019.2",
+		Title:             `Cannot marshal channels or functions`,
+		SeverityRationale: `encoding/json returns an error when marshaling channels and functions, so the data is never written.`,
+		Related:           []string{"SA1014", "SA9005"},
+		Since:             "2019.2",
 	},
 
 	"SA1027": {
@@ -242,13 +330,15 @@ can be relied upon to be 64-bit aligned.
 
 You can use the structlayout tool to inspect the alignment of fields
 in a struct.`,
-		Since: "2019.2",
+		SeverityRationale: `Misaligned 64-bit atomic operations crash the program on 32-bit platforms.`,
+		Since:             "2019.2",
 	},
 
 	"SA1028": {
-		Title: `sort.Slice can only be used on slices`,
-		Text:  `The first argument of sort.Slice must be a slice.`,
-		Since: "2020.1",
+		Title:             `sort.Slice can only be used on slices`,
+		Text:              `The first argument of sort.Slice must be a slice.`,
+		SeverityRationale: `sort.Slice panics when given anything other than a slice.`,
+		Since:             "2020.1",
 	},
 
 	"SA1029": {
@@ -262,12 +352,35 @@ To avoid allocating when assigning to an interface{},
 context keys often have concrete type struct{}. Alternatively,
 exported context key variables' static type should be a pointer or
 interface.`,
-		Since: "2020.1",
+		Before: `ctx = context.WithValue(ctx, "user", u)`,
+		After: `type userKey struct{}
+
+ctx = context.WithValue(ctx, userKey{}, u)`,
+		Related:           []string{"SA1012"},
+		SeverityRationale: `Keys of built-in types may collide with keys used by other packages, which silently overwrites their values.`,
+		Since:             "2020.1",
 	},
 
 	"SA2000": {
 		Title: `sync.WaitGroup.Add called inside the goroutine, leading to a race condition`,
-		Since: "2017.1",
+		Before: `for _, x := range xs {
+    go func(x T) {
+        wg.Add(1)
+        defer wg.Done()
+        process(x)
+    }(x)
+}
+wg.Wait()`,
+		After: `for _, x := range xs {
+    wg.Add(1)
+    go func(x T) {
+        defer wg.Done()
+        process(x)
+    }(x)
+}
+wg.Wait()`,
+		SeverityRationale: `Wait may return before the goroutines have called Add, which is a data race that tests rarely catch.`,
+		Since:             "2017.1",
 	},
 
 	"SA2001": {
@@ -288,17 +401,26 @@ simpler ways of achieving the same effect. When that isn't the case,
 the code should be amply commented to avoid confusion. Combining such
 comments with a //lint:ignore directive can be used to suppress this
 rare false positive.`,
-		Since: "2017.1",
+		Related:           []string{"SA2003"},
+		SeverityRationale: `The lock is released immediately, so the code that was meant to be protected runs without synchronization.`,
+		Since:             "2017.1",
 	},
 
 	"SA2002": {
-		Title: `Called testing.T.FailNow or SkipNow in a goroutine, which isn't allowed`,
-		Since: "2017.1",
+		Title:             `Called testing.T.FailNow or SkipNow in a goroutine, which isn't allowed`,
+		SeverityRationale: `FailNow only stops the goroutine it is called in; called from another goroutine, the test keeps running after it was supposed to stop.`,
+		Since:             "2017.1",
 	},
 
 	"SA2003": {
 		Title: `Deferred Lock right after locking, likely meant to defer Unlock instead`,
-		Since: "2017.1",
+		Before: `mu.Lock()
+defer mu.Lock()`,
+		After: `mu.Lock()
+defer mu.Unlock()`,
+		SeverityRationale: `The deferred call to Lock deadlocks when the function returns.`,
+		Related:           []string{"SA2001"},
+		Since:             "2017.1",
 	},
 
 	"SA3000": {
@@ -309,7 +431,16 @@ it is your responsibility to arrange for this, by calling os.Exit with
 the correct code. The correct code is returned by (*testing.M).Run, so
 the usual way of implementing TestMain is to end it with
 os.Exit(m.Run()).`,
-		Since: "2017.1",
+		Before: `func TestMain(m *testing.M) {
+    setup()
+    m.Run()
+}`,
+		After: `func TestMain(m *testing.M) {
+    setup()
+    os.Exit(m.Run())
+}`,
+		SeverityRationale: `Failing tests go unnoticed, because go test reports success.`,
+		Since:             "2017.1",
 	},
 
 	"SA3001": {
@@ -318,102 +449,224 @@ os.Exit(m.Run()).`,
 benchmarks and uses it in computations to determine the duration of a
 single operation. Benchmark code must not alter b.N as this would
 falsify results.`,
-		Since: "2017.1",
+		Before: `func BenchmarkFoo(b *testing.B) {
+    b.N = 1000
+    for i := 0; i < b.N; i++ {
+        foo()
+    }
+}`,
+		After: `func BenchmarkFoo(b *testing.B) {
+    for i := 0; i < b.N; i++ {
+        foo()
+    }
+}`,
+		SeverityRationale: `Assigning to b.N changes the number of iterations the benchmark framework relies on, which makes the reported results meaningless.`,
+		Since:             "2017.1",
 	},
 
 	"SA4000": {
-		Title: `Boolean expression has identical expressions on both sides`,
-		Since: "2017.1",
+		Title:             `Boolean expression has identical expressions on both sides`,
+		Before:            `if x.a == x.a {}`,
+		After:             `if x.a == y.a {}`,
+		SeverityRationale: `Such expressions have constant results and are almost always the result of a copy and paste mistake.`,
+		Since:             "2017.1",
 	},
 
 	"SA4001": {
-		Title: `&*x gets simplified to x, it does not copy x`,
-		Since: "2017.1",
+		Title:  `&*x gets simplified to x, it does not copy x`,
+		Before: `y := &*x`,
+		After: `v := *x
+y := &v`,
+		SeverityRationale: `Code that takes this shape usually meant to copy the value, but both expressions refer to the same memory.`,
+		Since:             "2017.1",
 	},
 
 	"SA4002": {
-		Title: `Comparing strings with known different sizes has predictable results`,
-		Since: "2017.1",
+		Title:             `Comparing strings with known different sizes has predictable results`,
+		Related:           []string{"SA4003"},
+		SeverityRationale: `The comparison always has the same result, which usually means that one of the operands is wrong.`,
+		Since:             "2017.1",
 	},
 
 	"SA4003": {
 		Title: `Comparing unsigned values against negative values is pointless`,
-		Since: "2017.1",
+		Before: `var x uint
+if x < 0 {}`,
+		After: `var x int
+if x < 0 {}`,
+		Related:           []string{"SA4002"},
+		SeverityRationale: `The condition is always true or always false, so one of the branches is dead code.`,
+		Since:             "2017.1",
 	},
 
 	"SA4004": {
 		Title: `The loop exits unconditionally after one iteration`,
-		Since: "2017.1",
+		Before: `for _, x := range xs {
+    if x.ok {
+        return x
+    }
+    return nil
+}`,
+		After: `for _, x := range xs {
+    if x.ok {
+        return x
+    }
+}
+return nil`,
+		SeverityRationale: `A loop that exits unconditionally is usually a misplaced return or break that prevents the remaining iterations from running.`,
+		Since:             "2017.1",
 	},
 
 	"SA4005": {
 		Title: `Field assignment that will never be observed. Did you mean to use a pointer receiver?`,
-		Since: "2017.1",
+		Before: `func (t T) SetName(name string) {
+    t.name = name
+}`,
+		After: `func (t *T) SetName(name string) {
+    t.name = name
+}`,
+		SeverityRationale: `The method modifies a copy of the receiver, so callers never see the change.`,
+		Since:             "2017.1",
 	},
 
 	"SA4006": {
 		Title: `A value assigned to a variable is never read before being overwritten. Forgotten error check or dead code?`,
-		Since: "2017.1",
+		Before: `x, err := foo()
+x, err = bar()
+if err != nil {
+    return err
+}`,
+		After: `x, err := foo()
+if err != nil {
+    return err
+}
+x, err = bar()
+if err != nil {
+    return err
+}`,
+		SeverityRationale: `Unused values are often errors that were never checked. When they aren't, they are dead code that misleads readers.`,
+		Related:           []string{"SA4009", "SA4010"},
+		Since:             "2017.1",
 	},
 
 	"SA4008": {
-		Title: `The variable in the loop condition never changes, are you incrementing the wrong variable?`,
-		Since: "2017.1",
+		Title:             `The variable in the loop condition never changes, are you incrementing the wrong variable?`,
+		Before:            `for i := 0; i < n; j++ {}`,
+		After:             `for i := 0; i < n; i++ {}`,
+		SeverityRationale: `The loop either never runs or never terminates.`,
+		Since:             "2017.1",
 	},
 
 	"SA4009": {
 		Title: `A function argument is overwritten before its first use`,
-		Since: "2017.1",
+		Before: `func fn(x int) {
+    x = 1
+    fmt.Println(x)
+}`,
+		After: `func fn(x int) {
+    fmt.Println(x)
+}`,
+		Related:           []string{"SA4006"},
+		SeverityRationale: `The value passed by the caller is never used, which usually means that the wrong variable is being assigned to.`,
+		Since:             "2017.1",
 	},
 
 	"SA4010": {
 		Title: `The result of append will never be observed anywhere`,
-		Since: "2017.1",
+		Before: `func add(s []int, x int) {
+    s = append(s, x)
+}`,
+		After: `func add(s []int, x int) []int {
+    return append(s, x)
+}`,
+		Related:           []string{"SA4006"},
+		SeverityRationale: `Work is done to build a slice that nobody reads, which usually means that the result was meant to be stored elsewhere.`,
+		Since:             "2017.1",
 	},
 
 	"SA4011": {
 		Title: `Break statement with no effect. Did you mean to break out of an outer loop?`,
-		Since: "2017.1",
+		Before: `for _, x := range xs {
+    switch x {
+    case target:
+        break
+    }
+}`,
+		After: `loop:
+for _, x := range xs {
+    switch x {
+    case target:
+        break loop
+    }
+}`,
+		SeverityRationale: `The break only exits the select or switch statement, not the surrounding loop, so the loop keeps running.`,
+		Since:             "2017.1",
 	},
 
 	"SA4012": {
-		Title: `Comparing a value against NaN even though no value is equal to NaN`,
-		Since: "2017.1",
+		Title:             `Comparing a value against NaN even though no value is equal to NaN`,
+		Before:            `if x == math.NaN() {}`,
+		After:             `if math.IsNaN(x) {}`,
+		SeverityRationale: `NaN is never equal to anything, including itself, so the comparison is always false.`,
+		Since:             "2017.1",
 	},
 
 	"SA4013": {
-		Title: `Negating a boolean twice (!!b) is the same as writing b. This is either redundant, or a typo.`,
-		Since: "2017.1",
+		Title:             `Negating a boolean twice (!!b) is the same as writing b. This is either redundant, or a typo.`,
+		Before:            `if !!ok {}`,
+		After:             `if ok {}`,
+		SeverityRationale: `Double negation is either redundant or a typo that inverted the intended condition.`,
+		Since:             "2017.1",
 	},
 
 	"SA4014": {
-		Title: `An if/else if chain has repeated conditions and no side-effects; if the condition didn't match the first time, it won't match the second time, either`,
-		Since: "2017.1",
+		Title:             `An if/else if chain has repeated conditions and no side-effects; if the condition didn't match the first time, it won't match the second time, either`,
+		Related:           []string{"SA4000"},
+		SeverityRationale: `The repeated branch can never run, which usually means that its condition was copied and not updated.`,
+		Since:             "2017.1",
 	},
 
 	"SA4015": {
-		Title: `Calling functions like math.Ceil on floats converted from integers doesn't do anything useful`,
-		Since: "2017.1",
+		Title:             `Calling functions like math.Ceil on floats converted from integers doesn't do anything useful`,
+		Before:            `math.Ceil(float64(a / b))`,
+		After:             `math.Ceil(float64(a) / float64(b))`,
+		SeverityRationale: `Integer division has already truncated the value by the time it is converted to a float.`,
+		Since:             "2017.1",
 	},
 
 	"SA4016": {
-		Title: `Certain bitwise operations, such as x ^ 0, do not do anything useful`,
-		Since: "2017.1",
+		Title:             `Certain bitwise operations, such as x ^ 0, do not do anything useful`,
+		Before:            `x = y ^ 0`,
+		After:             `x = y`,
+		SeverityRationale: `The operation leaves its operand unchanged, which usually means that the wrong constant was used.`,
+		Since:             "2017.1",
 	},
 
 	"SA4017": {
-		Title: `A pure function's return value is discarded, making the call pointless`,
-		Since: "2017.1",
+		Title:             `A pure function's return value is discarded, making the call pointless`,
+		Before:            `strings.TrimSpace(s)`,
+		After:             `s = strings.TrimSpace(s)`,
+		SeverityRationale: `Calling a pure function only for its result and then discarding the result means the intended effect never happens.`,
+		Related:           []string{"SA4010"},
+		Since:             "2017.1",
+		CheckOptions: &PureFunctionsOptions{
+			ExtraPureFuncs: []string{},
+		},
 	},
 
 	"SA4018": {
-		Title: `Self-assignment of variables`,
-		Since: "2017.1",
+		Title:             `Self-assignment of variables`,
+		Before:            `x = x`,
+		After:             `x = y`,
+		SeverityRationale: `Assigning a variable to itself has no effect and usually means that the wrong variable is being assigned to.`,
+		Since:             "2017.1",
 	},
 
 	"SA4019": {
-		Title: `Multiple, identical build constraints in the same file`,
-		Since: "2017.1",
+		Title:             `Multiple, identical build constraints in the same file`,
+		Related:           []string{"ST1019"},
+		SeverityRationale: `Duplicate build constraints are redundant and often a sign that a different constraint was intended.`,
+		Since:             "2017.1",
 	},
 
 	"SA4020": {
@@ -484,33 +737,61 @@ the following type switch will have an unreachable case clause:
 
 T will always match before V because they are structurally equivalent
 and therefore doSomething()'s return value implements both.`,
-		Since: "2019.2",
+		SeverityRationale: `Clauses that can never match are dead code, and the values they were meant to handle are handled by an earlier clause.`,
+		Since:             "2019.2",
 	},
 
 	"SA4021": {
-		Title: `x = append(y) is equivalent to x = y`,
-		Since: "2019.2",
+		Title:             `x = append(y) is equivalent to x = y`,
+		Before:            `x = append(y)`,
+		After:             `x = append(y, elem)`,
+		Related:           []string{"SA4010"},
+		SeverityRationale: `Appending nothing returns the original slice, so the call does nothing, which usually means that an argument is missing.`,
+		Since:             "2019.2",
 	},
 
 	"SA4022": {
-		Title: `Comparing the address of a variable against nil`,
-		Text:  `Code such as 'if &x == nil' is meaningless, because taking the address of a variable always yields a non-nil pointer.`,
-		Since: "2020.1",
+		Title:             `Comparing the address of a variable against nil`,
+		Text:              `Code such as 'if &x == nil' is meaningless, because taking the address of a variable always yields a non-nil pointer.`,
+		Before:            `if &x == nil {}`,
+		After:             `if x == nil {}`,
+		SeverityRationale: `The address of a variable is never nil, so the condition is always true or always false.`,
+		Since:             "2020.1",
 	},
 
 	"SA5000": {
 		Title: `Assignment to nil map`,
-		Since: "2017.1",
+		Before: `var m map[string]int
+m["a"] = 1`,
+		After: `m := map[string]int{}
+m["a"] = 1`,
+		SeverityRationale: `Assigning to a nil map always panics.`,
+		Since:             "2017.1",
 	},
 
 	"SA5001": {
 		Title: `Defering Close before checking for a possible error`,
-		Since: "2017.1",
+		Before: `f, err := os.Open(name)
+defer f.Close()
+if err != nil {
+    return err
+}`,
+		After: `f, err := os.Open(name)
+if err != nil {
+    return err
+}
+defer f.Close()`,
+		SeverityRationale: `If the error is non-nil, the deferred Close is called on a nil or invalid value, which may panic.`,
+		Since:             "2017.1",
 	},
 
 	"SA5002": {
-		Title: `The empty for loop (for {}) spins and can block the scheduler`,
-		Since: "2017.1",
+		Title:             `The empty for loop (for {}) spins and can block the scheduler`,
+		Before:            `for {}`,
+		After:             `select {}`,
+		Related:           []string{"SA5004"},
+		SeverityRationale: `A goroutine spinning in an empty loop burns CPU, and on older versions of Go it can prevent other goroutines from running.`,
+		Since:             "2017.1",
 	},
 
 	"SA5003": {
@@ -518,12 +799,26 @@ and therefore doSomething()'s return value implements both.`,
 		Text: `Defers are scoped to the surrounding function, not the surrounding
 block. In a function that never returns, i.e. one containing an
 infinite loop, defers will never execute.`,
-		Since: "2017.1",
+		Related:           []string{"SA9001"},
+		SeverityRationale: `Deferred calls only run when the function returns, which never happens, so resources are never released.`,
+		Since:             "2017.1",
 	},
 
 	"SA5004": {
 		Title: `for { select { ... with an empty default branch spins`,
-		Since: "2017.1",
+		Before: `for {
+    select {
+    case <-ch:
+        handle()
+    default:
+    }
+}`,
+		After: `for range ch {
+    handle()
+}`,
+		Related:           []string{"SA5002"},
+		SeverityRationale: `The loop spins instead of blocking, wasting CPU time while it waits for channel operations.`,
+		Since:             "2017.1",
 	},
 
 	"SA5005": {
@@ -539,12 +834,20 @@ and the object will never be collected, leading to a memory leak. That
 is why the finalizer should instead use its first argument to operate
 on the object. That way, the number of references can temporarily go
 to zero before the object is being passed to the finalizer.`,
-		Since: "2017.1",
+		Before: `runtime.SetFinalizer(obj, func(_ *T) {
+    obj.Close()
+})`,
+		After: `runtime.SetFinalizer(obj, func(obj *T) {
+    obj.Close()
+})`,
+		SeverityRationale: `Objects that reference themselves through their finalizer are never collected, so the finalizer never runs and memory leaks.`,
+		Since:             "2017.1",
 	},
 
 	"SA5006": {
-		Title: `Slice index out of bounds`,
-		Since: "2017.1",
+		Title:             `Slice index out of bounds`,
+		SeverityRationale: `Indexing a slice out of bounds always panics.`,
+		Since:             "2017.1",
 	},
 
 	"SA5007": {
@@ -558,17 +861,29 @@ exit condition. It can also happen "on purpose". Some languages have
 tail call optimization which makes certain infinite recursive calls
 safe to use. Go, however, does not implement TCO, and as such a loop
 should be used instead.`,
-		Since: "2017.1",
+		SeverityRationale: `The program crashes once the stack is exhausted.`,
+		Since:             "2017.1",
 	},
 
 	"SA5008": {
 		Title: `Invalid struct tag`,
-		Since: "2019.2",
+		Before: `type T struct {
+    Name string ` + "`" + `json:name` + "`" + `
+}`,
+		After: `type T struct {
+    Name string ` + "`" + `json:"name"` + "`" + `
+}`,
+		SeverityRationale: `Packages such as encoding/json silently ignore malformed struct tags.`,
+		Since:             "2019.2",
 	},
 
 	"SA5009": {
-		Title: `Invalid Printf call`,
-		Since: "2019.2",
+		Title:             `Invalid Printf call`,
+		Before:            `fmt.Printf("%d", "hello")`,
+		After:             `fmt.Printf("%s", "hello")`,
+		Related:           []string{"SA1006"},
+		SeverityRationale: `Mismatched verbs and arguments produce garbled output, such as %!d(string=...), instead of the intended text.`,
+		Since:             "2019.2",
 	},
 
 	"SA5010": {
@@ -590,7 +905,8 @@ another. If both interface types contain the same method but with
 different signatures, then the type assertion can never succeed,
 either.`,
 
-		Since: "2020.1",
+		SeverityRationale: `The assertion always fails; in its single-value form, it always panics.`,
+		Since:             "2020.1",
 	},
 
 	"SA5011": {
@@ -658,7 +974,8 @@ workaround is to modify the definition of Fatal like so:
 We also hard-code functions from common logging packages such as
 logrus. Please file an issue if we're missing support for a
 popular package.`,
-		Since: "2020.1",
+		SeverityRationale: `If the pointer can be nil, the dereference panics.`,
+		Since:             "2020.1",
 	},
 
 	"SA5012": {
@@ -667,12 +984,28 @@ popular package.`,
 Often, these functions treat elements in a slice as pairs. 
 For example, strings.NewReplacer takes pairs of old and new strings, 
 and calling it with an odd number of elements would be an error.`,
-		Since: "Unreleased",
+		Before:            `strings.NewReplacer("a", "b", "c")`,
+		After:             `strings.NewReplacer("a", "b", "c", "d")`,
+		SeverityRationale: `Functions such as strings.NewReplacer panic when called with an odd number of arguments.`,
+		Since:             "Unreleased",
 	},
 
 	"SA6000": {
 		Title: `Using regexp.Match or related in a loop, should use regexp.Compile`,
-		Since: "2017.1",
+		Before: `for _, s := range lines {
+    if ok, _ := regexp.MatchString(` + "`" + `^\d+$` + "`" + `, s); ok {
+        n++
+    }
+}`,
+		After: `re := regexp.MustCompile(` + "`" + `^\d+$` + "`" + `)
+for _, s := range lines {
+    if re.MatchString(s) {
+        n++
+    }
+}`,
+		SeverityRationale: `The code is correct, but compiles the same regular expression over and over again.`,
+		Related:           []string{"SA1000"},
+		Since:             "2017.1",
 	},
 
 	"SA6001": {
@@ -702,7 +1035,9 @@ one does not.
 
 For some history on this optimization, check out commit
 f5f5a8b6209f84961687d993b93ea0d397f5d5bf in the Go repository.`,
-		Since: "2017.1",
+		Related:           []string{"S1030"},
+		SeverityRationale: `The code is correct, but allocates a string for every map lookup.`,
+		Since:             "2017.1",
 	},
 
 	"SA6002": {
@@ -719,7 +1054,12 @@ pointer to the slice instead.
 
 See the comments on https://go-review.googlesource.com/c/go/+/24371
 that discuss this problem.`,
-		Since: "2017.1",
+		Before: `buf := make([]byte, 1024)
+pool.Put(buf)`,
+		After: `buf := make([]byte, 1024)
+pool.Put(&buf)`,
+		SeverityRationale: `The code is correct, but every call to Put allocates, defeating the point of the pool.`,
+		Since:             "2017.1",
 	},
 
 	"SA6003": {
@@ -741,7 +1081,9 @@ Do note that if you are interested in the indices, ranging over a
 string and over a slice of runes will yield different indices. The
 first one yields byte offsets, while the second one yields indices in
 the slice of runes.`,
-		Since: "2017.1",
+		Related:           []string{"S1029"},
+		SeverityRationale: `The code is correct, but the conversion allocates a slice that ranging over the string avoids.`,
+		Since:             "2017.1",
 	},
 
 	"SA6005": {
@@ -765,22 +1107,49 @@ been found.
 
 For a more in-depth explanation of this issue, see
 https://blog.digitalocean.com/how-to-efficiently-compare-strings-in-go/`,
-		Since: "2019.2",
+		Before:            `if strings.ToLower(s1) == strings.ToLower(s2) {}`,
+		After:             `if strings.EqualFold(s1, s2) {}`,
+		SeverityRationale: `The code is correct, but allocates two new strings for every comparison.`,
+		Since:             "2019.2",
 	},
 
 	"SA9001": {
 		Title: `Defers in range loops may not run when you expect them to`,
-		Since: "2017.1",
+		Before: `for _, path := range paths {
+    f, err := os.Open(path)
+    if err != nil {
+        return err
+    }
+    defer f.Close()
+    process(f)
+}`,
+		After: `for _, path := range paths {
+    if err := processFile(path); err != nil {
+        return err
+    }
+}`,
+		SeverityRationale: `Deferred calls run when the function returns, not at the end of each iteration, which can exhaust resources such as file descriptors.`,
+		Related:           []string{"SA5003"},
+		Since:             "2017.1",
 	},
 
 	"SA9002": {
-		Title: `Using a non-octal os.FileMode that looks like it was meant to be in octal.`,
-		Since: "2017.1",
+		Title:             `Using a non-octal os.FileMode that looks like it was meant to be in octal.`,
+		Before:            `os.MkdirAll(dir, 755)`,
+		After:             `os.MkdirAll(dir, 0755)`,
+		SeverityRationale: `The decimal number 755 corresponds to the permissions -wxrw--wt, which are almost certainly not intended.`,
+		Since:             "2017.1",
 	},
 
 	"SA9003": {
 		Title: `Empty body in an if or else branch`,
-		Since: "2017.1",
+		Before: `if err != nil {
+}`,
+		After: `if err != nil {
+    return err
+}`,
+		SeverityRationale: `Empty branches are often unfinished code. When they are intentional, a comment is clearer.`,
+		Since:             "2017.1",
 	},
 
 	"SA9004": {
@@ -872,7 +1241,16 @@ This code will output
     2
 
 as EnumSecond has no explicit type, and thus defaults to int.`,
-		Since: "2019.1",
+		Before: `const (
+    EnumFirst  EnumType = 1
+    EnumSecond          = 2
+)`,
+		After: `const (
+    EnumFirst  EnumType = 1
+    EnumSecond EnumType = 2
+)`,
+		SeverityRationale: `The remaining constants are untyped, which is rarely intended and weakens type checking of their uses.`,
+		Since:             "2019.1",
 	},
 
 	"SA9005": {
@@ -884,7 +1262,19 @@ to (un)marshal structs that only consist of unexported fields.
 This check will not flag calls involving types that define custom
 marshaling behavior, e.g. via MarshalJSON methods. It will also not
 flag empty structs.`,
-		Since: "2019.2",
+		Before: `type T struct {
+    name string
+}
+
+json.Marshal(T{name: "x"})`,
+		After: `type T struct {
+    Name string
+}
+
+json.Marshal(T{Name: "x"})`,
+		Related:           []string{"SA1014", "SA1026"},
+		SeverityRationale: `Unexported fields are ignored by the marshaler, so the output contains none of the struct's data.`,
+		Since:             "2019.2",
 	},
 
 	"SA9006": {
@@ -908,5 +1298,8 @@ positives in somewhat exotic but valid bit twiddling tricks:
         v = v << 32
         return i-v
     }`,
+		Before: `v := int8(42)
+v >>= 8`,
+		SeverityRationale: `Shifting a value by at least its size always produces zero or the sign, which is almost certainly not intended.`,
 	},
 }
@@ -23,6 +23,7 @@ import (
 	"honnef.co/go/tools/analysis/facts"
 	"honnef.co/go/tools/analysis/lint"
 	"honnef.co/go/tools/analysis/report"
+	"honnef.co/go/tools/config"
 	"honnef.co/go/tools/go/ast/astutil"
 	"honnef.co/go/tools/go/ir"
 	"honnef.co/go/tools/go/ir/irutil"
@@ -2838,8 +2839,18 @@ func CheckNonOctalFileMode(pass *analysis.Pass) (interface{}, error) {
 	return nil, nil
 }
 
+// PureFunctionsOptions are the options of SA4017.
+type PureFunctionsOptions struct {
+	ExtraPureFuncs []string `toml:"extra_pure_funcs" doc:"Additional functions to treat as pure, such as \"example.com/pkg.Func\" or \"(*example.com/pkg.T).Method\""`
+}
+
 func CheckPureFunctions(pass *analysis.Pass) (interface{}, error) {
 	pure := pass.ResultOf[facts.Purity].(facts.PurityResult)
+	opts := config.For(pass).OptionsFor(pass.Analyzer.Name).(*PureFunctionsOptions)
+	extraPure := map[string]bool{}
+	for _, name := range opts.ExtraPureFuncs {
+		extraPure[name] = true
+	}
 
 fnLoop:
 	for _, fn := range pass.ResultOf[buildir.Analyzer].(*buildir.IR).SrcFuncs {
@@ -2879,8 +2890,9 @@ fnLoop:
 					// TODO(dh): support anonymous functions
 					continue
 				}
-				if _, ok := pure[callee.Object().(*types.Func)]; ok {
-					if pass.Pkg.Path() == "fmt_test" && callee.Object().(*types.Func).FullName() == "fmt.Sprintf" {
+				obj := callee.Object().(*types.Func)
+				if _, ok := pure[obj]; ok || extraPure[obj.FullName()] {
+					if pass.Pkg.Path() == "fmt_test" && obj.FullName() == "fmt.Sprintf" {
 						// special case for benchmarks in the fmt package
 						continue
 					}
@@ -2892,8 +2904,14 @@ fnLoop:
 	return nil, nil
 }
 
+// DeprecatedOptions are the options of SA1019.
+type DeprecatedOptions struct {
+	ExtraDeprecated map[string]string `toml:"extra_deprecated" doc:"Additional deprecated objects, such as \"example.com/pkg.Func\" or \"(*example.com/pkg.T).Method\", mapped to an explanation"`
+}
+
 func CheckDeprecated(pass *analysis.Pass) (interface{}, error) {
 	deprs := pass.ResultOf[facts.Deprecated].(facts.DeprecatedResult)
+	extraDeprs := config.For(pass).OptionsFor(pass.Analyzer.Name).(*DeprecatedOptions).ExtraDeprecated
 
 	// Selectors can appear outside of function literals, e.g. when
 	// declaring package level variables.
@@ -2947,6 +2965,18 @@ func CheckDeprecated(pass *analysis.Pass) (interface{}, error) {
 			report.Report(pass, sel, fmt.Sprintf("%s is deprecated: %s", report.Render(pass, sel), depr.Msg))
 			return true
 		}
+		if msg, ok := extraDeprs[deprecationName(obj)]; ok {
+			if tfn != nil {
+				if _, ok := deprs.Objects[tfn]; ok {
+					return true
+				}
+			}
+			if msg == "" {
+				report.Report(pass, sel, fmt.Sprintf("%s is deprecated", report.Render(pass, sel)))
+			} else {
+				report.Report(pass, sel, fmt.Sprintf("%s is deprecated: %s", report.Render(pass, sel), msg))
+			}
+		}
 		return true
 	}
 
@@ -2976,6 +3006,20 @@ func CheckDeprecated(pass *analysis.Pass) (interface{}, error) {
 	return nil, nil
 }
 
+// deprecationName returns the name by which obj can be listed in
+// SA1019's extra_deprecated option, such as "(*bytes.Buffer).String"
+// or "io/ioutil.ReadAll". It returns the empty string for objects
+// that aren't functions or package-level objects.
+func deprecationName(obj types.Object) string {
+	if fn, ok := obj.(*types.Func); ok {
+		return fn.FullName()
+	}
+	if obj.Pkg() != nil && obj.Parent() == obj.Pkg().Scope() {
+		return objectName(obj)
+	}
+	return ""
+}
+
 func callChecker(rules map[string]CallCheck) func(pass *analysis.Pass) (interface{}, error) {
 	return func(pass *analysis.Pass) (interface{}, error) {
 		return checkCalls(pass, rules)
@@ -28,7 +28,7 @@ var Analyzers = lint.InitializeAnalyzers(Docs, map[string]*analysis.Analyzer{
 	},
 	"ST1006": {
 		Run:      CheckReceiverNames,
-		Requires: []*analysis.Analyzer{buildir.Analyzer, facts.Generated},
+		Requires: []*analysis.Analyzer{buildir.Analyzer, facts.Generated, config.Analyzer},
 	},
 	"ST1008": {
 		Run:      CheckErrorReturn,
@@ -8,8 +8,13 @@ var Docs = map[string]*lint.Documentation{
 		Text: `Packages must have a package comment that is formatted according to
 the guidelines laid out in
 https://github.com/golang/go/wiki/CodeReviewComments#package-comments.`,
-		Since:      "2019.1",
-		NonDefault: true,
+		Before: `package bytes`,
+		After: `// Package bytes implements functions for the manipulation of byte slices.
+package bytes`,
+		SeverityRationale: `Missing package comments only affect documentation, and not every package needs one, which is why the check is disabled by default.`,
+		Related:           []string{"ST1020", "ST1021", "ST1022"},
+		Since:             "2019.1",
+		NonDefault:        true,
 	},
 
 	"ST1001": {
@@ -38,8 +43,15 @@ Quoting Go Code Review Comments:
     programs. It makes the programs much harder to read because it is
     unclear whether a name like Quux is a top-level identifier in the
     current package or in an imported package.`,
-		Since:   "2019.1",
-		Options: []string{"dot_import_whitelist"},
+		Before: `import . "strings"
+
+var s = ToUpper(x)`,
+		After: `import "strings"
+
+var s = strings.ToUpper(x)`,
+		SeverityRationale: `Dot imports make it unclear where identifiers come from, but they don't change what the code does.`,
+		Since:             "2019.1",
+		Options:           []string{"dot_import_whitelist"},
 	},
 
 	"ST1003": {
@@ -52,9 +64,14 @@ See the following links for details:
 - https://golang.org/doc/effective_go.html#mixed-caps
 - https://github.com/golang/go/wiki/CodeReviewComments#initialisms
 - https://github.com/golang/go/wiki/CodeReviewComments#variable-names`,
-		Since:      "2019.1",
-		NonDefault: true,
-		Options:    []string{"initialisms"},
+		Before: `var userId int
+func get_url() string`,
+		After: `var userID int
+func getURL() string`,
+		SeverityRationale: `Renaming exported identifiers breaks users of a package, and many code bases have established naming conventions, which is why the check is disabled by default.`,
+		Since:             "2019.1",
+		NonDefault:        true,
+		Options:           []string{"initialisms"},
 	},
 
 	"ST1005": {
@@ -70,7 +87,10 @@ Quoting Go Code Review Comments:
     fmt.Errorf("something bad") not fmt.Errorf("Something bad"), so
     that log.Printf("Reading %s: %v", filename, err) formats without a
     spurious capital letter mid-message.`,
-		Since: "2019.1",
+		Before:            `errors.New("Something bad happened.")`,
+		After:             `errors.New("something bad happened")`,
+		SeverityRationale: `Error strings that are capitalized or end in punctuation read badly when they are wrapped in other errors; they don't affect behavior.`,
+		Since:             "2019.1",
 	},
 
 	"ST1006": {
@@ -88,13 +108,23 @@ Quoting Go Code Review Comments:
     almost every line of every method of the type; familiarity admits
     brevity. Be consistent, too: if you call the receiver "c" in one
     method, don't call it "cl" in another.`,
-		Since: "2019.1",
+		Before:            `func (self *Client) Do() {}`,
+		After:             `func (c *Client) Do() {}`,
+		Related:           []string{"ST1016"},
+		SeverityRationale: `Generic receiver names like self or this are unidiomatic and carry no information, but they are harmless.`,
+		Since:             "2019.1",
+		CheckOptions: &ReceiverNamesOptions{
+			AllowedReceiverNames: []string{},
+		},
 	},
 
 	"ST1008": {
-		Title: `A function's error value should be its last return value`,
-		Text:  `A function's error value should be its last return value.`,
-		Since: `2019.1`,
+		Title:             `A function's error value should be its last return value`,
+		Text:              `A function's error value should be its last return value.`,
+		Before:            `func fn() (error, int)`,
+		After:             `func fn() (int, error)`,
+		SeverityRationale: `Callers expect the error to come last; any other position works, but is easy to misread.`,
+		Since:             `2019.1`,
 	},
 
 	"ST1011": {
@@ -104,14 +134,20 @@ as a count of nanoseconds. An expression like 5 * time.Microsecond
 yields the value 5000. It is therefore not appropriate to suffix a
 variable of type time.Duration with any time unit, such as Msec or
 Milli.`,
-		Since: `2019.1`,
+		Before:            `var timeoutSecs time.Duration = 5 * time.Second`,
+		After:             `var timeout time.Duration = 5 * time.Second`,
+		SeverityRationale: `Unit suffixes are misleading because a time.Duration carries its own unit, but the code still behaves correctly.`,
+		Since:             `2019.1`,
 	},
 
 	"ST1012": {
 		Title: `Poorly chosen name for error variable`,
 		Text: `Error variables that are part of an API should be called errFoo or
 ErrFoo.`,
-		Since: "2019.1",
+		Before:            `var NotFound = errors.New("not found")`,
+		After:             `var ErrNotFound = errors.New("not found")`,
+		SeverityRationale: `Following the naming convention makes error variables easy to recognize; the name has no effect on behavior.`,
+		Since:             "2019.1",
 	},
 
 	"ST1013": {
@@ -122,19 +158,39 @@ package provides constants for all status codes that are part of the
 various specifications. It is recommended to use these constants
 instead of hard-coding magic numbers, to vastly improve the
 readability of your code.`,
-		Since:   "2019.1",
-		Options: []string{"http_status_code_whitelist"},
+		Before:            `w.WriteHeader(418)`,
+		After:             `w.WriteHeader(http.StatusTeapot)`,
+		SeverityRationale: `Magic numbers are harder to read and to search for than the named constants, but they have the same values.`,
+		Since:             "2019.1",
+		Options:           []string{"http_status_code_whitelist"},
 	},
 
 	"ST1015": {
 		Title: `A switch's default case should be the first or last case`,
-		Since: "2019.1",
+		Before: `switch x {
+case 1:
+default:
+case 2:
+}`,
+		After: `switch x {
+case 1:
+case 2:
+default:
+}`,
+		SeverityRationale: `A default case in the middle of a switch is easy to overlook, even though its position doesn't affect which case runs.`,
+		Since:             "2019.1",
 	},
 
 	"ST1016": {
-		Title:      `Use consistent method receiver names`,
-		Since:      "2019.1",
-		NonDefault: true,
+		Title: `Use consistent method receiver names`,
+		Before: `func (c *Client) Get() {}
+func (cl *Client) Post() {}`,
+		After: `func (c *Client) Get() {}
+func (c *Client) Post() {}`,
+		SeverityRationale: `Receiver names are a matter of taste, and renaming them in existing code creates churn, which is why the check is disabled by default.`,
+		Related:           []string{"ST1006"},
+		Since:             "2019.1",
+		NonDefault:        true,
 	},
 
 	"ST1017": {
@@ -144,12 +200,16 @@ literal is on the left side of the comparison. These are a common
 idiom in languages in which assignment is an expression, to avoid bugs
 of the kind 'if (x = 42)'. In Go, which doesn't allow for this kind of
 bug, we prefer the more idiomatic 'if x == 42'.`,
-		Since: "2019.2",
+		Before:            `if 42 == x {}`,
+		After:             `if x == 42 {}`,
+		SeverityRationale: `Yoda conditions are harder to read than their natural ordering, and Go's compiler already rejects accidental assignments in conditions.`,
+		Since:             "2019.2",
 	},
 
 	"ST1018": {
-		Title: `Avoid zero-width and control characters in string literals`,
-		Since: "2019.2",
+		Title:             `Avoid zero-width and control characters in string literals`,
+		SeverityRationale: `Invisible characters make string literals look different from what they contain, which can hide mistakes and confuse readers.`,
+		Since:             "2019.2",
 	},
 
 	"ST1019": {
@@ -175,7 +235,14 @@ intentionally (see for example
 https://github.com/golang/go/commit/3409ce39bfd7584523b7a8c150a310cea92d879d)
 – if you want to allow this pattern in your code base, you're
 advised to disable this check.`,
-		Since: "2020.1",
+		Before: `import (
+    "fmt"
+    format "fmt"
+)`,
+		After:             `import "fmt"`,
+		Related:           []string{"SA4019"},
+		SeverityRationale: `Importing a package more than once is redundant and makes it harder to tell which name the code uses.`,
+		Since:             "2020.1",
 	},
 
 	"ST1020": {
@@ -191,8 +258,14 @@ through grep.
 
 See https://golang.org/doc/effective_go.html#commentary for more
 information on how to write good documentation.`,
-		Since:      "2020.1",
-		NonDefault: true,
+		Before: `// Returns the sum of x and y.
+func Add(x, y int) int`,
+		After: `// Add returns the sum of x and y.
+func Add(x, y int) int`,
+		SeverityRationale: `Not all projects follow this convention, which is why the check is disabled by default.`,
+		Related:           []string{"ST1000", "ST1021", "ST1022"},
+		Since:             "2020.1",
+		NonDefault:        true,
 	},
 
 	"ST1021": {
@@ -208,8 +281,14 @@ through grep.
 
 See https://golang.org/doc/effective_go.html#commentary for more
 information on how to write good documentation.`,
-		Since:      "2020.1",
-		NonDefault: true,
+		Before: `// A client for the API.
+type Client struct{}`,
+		After: `// Client is a client for the API.
+type Client struct{}`,
+		Related:           []string{"ST1000", "ST1020", "ST1022"},
+		SeverityRationale: `Many projects document types without repeating their names, which is why the check is disabled by default.`,
+		Since:             "2020.1",
+		NonDefault:        true,
 	},
 
 	"ST1022": {
@@ -225,7 +304,13 @@ through grep.
 
 See https://golang.org/doc/effective_go.html#commentary for more
 information on how to write good documentation.`,
-		Since:      "2020.1",
-		NonDefault: true,
+		Before: `// The default timeout.
+const DefaultTimeout = time.Minute`,
+		After: `// DefaultTimeout is the default timeout.
+const DefaultTimeout = time.Minute`,
+		Related:           []string{"ST1000", "ST1020", "ST1021"},
+		SeverityRationale: `Documentation of variables and constants is often brief and informal, and not all projects follow this convention, which is why the check is disabled by default.`,
+		Since:             "2020.1",
+		NonDefault:        true,
 	},
 }
@@ -257,8 +257,17 @@ func CheckUnexportedReturn(pass *analysis.Pass) (interface{}, error) {
 	return nil, nil
 }
 
+// ReceiverNamesOptions are the options of ST1006.
+type ReceiverNamesOptions struct {
+	AllowedReceiverNames []string `toml:"allowed_receiver_names" doc:"Receiver names that are allowed even though they are generic, such as \"self\""`
+}
+
 func CheckReceiverNames(pass *analysis.Pass) (interface{}, error) {
 	irpkg := pass.ResultOf[buildir.Analyzer].(*buildir.IR).Pkg
+	allowed := map[string]bool{}
+	for _, name := range config.For(pass).OptionsFor(pass.Analyzer.Name).(*ReceiverNamesOptions).AllowedReceiverNames {
+		allowed[name] = true
+	}
 	for _, m := range irpkg.Members {
 		if T, ok := m.Object().(*types.TypeName); ok && !T.IsAlias() {
 			ms := gotypeutil.IntuitiveMethodSet(T.Type(), nil)
@@ -269,7 +278,7 @@ func CheckReceiverNames(pass *analysis.Pass) (interface{}, error) {
 					// skip embedded methods
 					continue
 				}
-				if recv.Name() == "self" || recv.Name() == "this" {
+				if (recv.Name() == "self" || recv.Name() == "this") && !allowed[recv.Name()] {
 					report.Report(pass, recv, `receiver name should be a reflection of its identity; don't use generic names such as "this" or "self"`, report.FilterGenerated())
 				}
 				if recv.Name() == "_" {
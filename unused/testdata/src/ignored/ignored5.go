@@ -0,0 +1,15 @@
+package pkg
+
+//lint:ignore-block U1000 everything in this declaration is used
+var (
+	v1 int // used
+	v2 int // used
+)
+
+var v3 int // unused
+
+//lint:ignore-block U1000 consider the type and its fields used
+type t10 struct { // used
+	a int // used
+	b int // used
+}
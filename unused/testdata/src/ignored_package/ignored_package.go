@@ -0,0 +1,8 @@
+// Package pkg is entirely ignored.
+//
+//lint:package-ignore U1000 consider everything in this package used
+package pkg
+
+type t1 struct{} // used
+
+func fn() {} // used
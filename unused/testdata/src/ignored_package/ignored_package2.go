@@ -0,0 +1,3 @@
+package pkg
+
+var v1 int // used
@@ -0,0 +1,3 @@
+// Package pkg isn't ignored, because the directive isn't part of its
+// documentation.
+package pkg //lint:package-ignore U1000 not part of the package's documentation
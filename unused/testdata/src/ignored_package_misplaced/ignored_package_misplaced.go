@@ -0,0 +1,5 @@
+package pkg
+
+type t1 struct{} // unused
+
+func fn() {} // unused
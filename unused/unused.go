@@ -1158,8 +1158,14 @@ func (g *graph) entry(pkg *pkg) {
 		line int
 	}
 	ignores := map[ignoredKey]struct{}{}
+	// the nodes annotated with //lint:ignore-block U1000
+	var ignoredBlocks []ast.Node
+	// whether the package is annotated with //lint:package-ignore U1000
+	ignoredPackage := false
 	for _, dir := range g.pkg.Directives {
-		if dir.Command != "ignore" && dir.Command != "file-ignore" {
+		switch dir.Command {
+		case "ignore", "ignore-block", "file-ignore", "package-ignore":
+		default:
 			continue
 		}
 		if len(dir.Arguments) == 0 {
@@ -1168,27 +1174,34 @@ func (g *graph) entry(pkg *pkg) {
 		for _, check := range strings.Split(dir.Arguments[0], ",") {
 			if check == "U1000" {
 				pos := g.pkg.Fset.PositionFor(dir.Node.Pos(), false)
-				var key ignoredKey
 				switch dir.Command {
 				case "ignore":
-					key = ignoredKey{
+					ignores[ignoredKey{
 						pos.Filename,
 						pos.Line,
-					}
+					}] = struct{}{}
+				case "ignore-block":
+					ignoredBlocks = append(ignoredBlocks, dir.Node)
 				case "file-ignore":
-					key = ignoredKey{
+					ignores[ignoredKey{
 						pos.Filename,
 						-1,
+					}] = struct{}{}
+				case "package-ignore":
+					// As for all other checks, the directive must be
+					// part of the package's documentation, i.e.
+					// precede the package clause.
+					dirPos := g.pkg.Fset.PositionFor(dir.Directive.Pos(), false)
+					if _, ok := dir.Node.(*ast.File); ok && dirPos.Line < pos.Line {
+						ignoredPackage = true
 					}
 				}
-
-				ignores[key] = struct{}{}
 				break
 			}
 		}
 	}
 
-	if len(ignores) > 0 {
+	if len(ignores) > 0 || len(ignoredBlocks) > 0 || ignoredPackage {
 		// all objects annotated with a //lint:ignore U1000 are considered used
 		for obj := range g.Nodes {
 			if obj, ok := obj.(types.Object); ok {
@@ -1205,6 +1218,15 @@ func (g *graph) entry(pkg *pkg) {
 				if !ok {
 					_, ok = ignores[key2]
 				}
+				if !ok {
+					ok = ignoredPackage
+				}
+				for _, node := range ignoredBlocks {
+					if ok {
+						break
+					}
+					ok = obj.Pos() >= node.Pos() && obj.Pos() < node.End()
+				}
 				if ok {
 					g.use(obj, nil, edgeIgnored)
 
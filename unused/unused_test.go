@@ -144,6 +144,8 @@ func TestAll(t *testing.T) {
 		"fields",
 		"functions",
 		"ignored",
+		"ignored_package",
+		"ignored_package_misplaced",
 		"interfaces",
 		"interfaces2",
 		"linkname",